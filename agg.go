@@ -68,6 +68,7 @@ func aggregateFileLumis(records []mongo.DASRecord) []mongo.DASRecord {
 	emap := make(map[string][]int64)
 	rmap := make(map[string][]int64)
 	var das mongo.DASRecord
+	var files []string // files in order of DAS records
 	for _, r := range records {
 		das = r["das"].(mongo.DASRecord)
 		var fileLumi FileLumi
@@ -103,6 +104,7 @@ func aggregateFileLumis(records []mongo.DASRecord) []mongo.DASRecord {
 			} else {
 				arr := []int64{lumi}
 				amap[file] = arr
+				files = append(files, file)
 			}
 		}
 		if run > 0 {
@@ -130,7 +132,8 @@ func aggregateFileLumis(records []mongo.DASRecord) []mongo.DASRecord {
 	}
 
 	var out []mongo.DASRecord
-	for file, lumis := range amap {
+	for _, file := range files {
+		lumis := amap[file]
 		rec := make(mongo.DASRecord)
 		rec["das"] = das
 		frec := make(mongo.DASRecord)
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+)
+
+// helper function to convert value of DAS record into int64 or float64
+// number, it returns false for non-numeric values
+func numberOf(val interface{}) (interface{}, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return i, true
+		}
+		if f, err := v.Float64(); err == nil {
+			return f, true
+		}
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return v, true
+	}
+	return nil, false
+}
+
+// helper function to convert number into float64
+func floatOf(val interface{}) float64 {
+	switch v := val.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// helper function to calculate value of aggregator function, integer
+// values yield integer sum, min and max while avg, median and stddev are
+// floating point numbers, functions of empty list of values are null
+func aggregate(fagg string, values []interface{}) interface{} {
+	var nums []interface{}
+	isInt := true
+	for _, val := range values {
+		if num, ok := numberOf(val); ok {
+			nums = append(nums, num)
+			if _, ok := num.(int64); !ok {
+				isInt = false
+			}
+		}
+	}
+	switch fagg {
+	case "count":
+		return int64(len(values))
+	case "sum":
+		// sum of non-numeric values, e.g. file names, is undefined
+		if len(nums) == 0 && len(values) > 0 {
+			return nil
+		}
+		if isInt {
+			var sum int64
+			for _, num := range nums {
+				sum += num.(int64)
+			}
+			return sum
+		}
+		var sum float64
+		for _, num := range nums {
+			sum += floatOf(num)
+		}
+		return sum
+	case "min", "max":
+		// non-numeric values, e.g. file names, are compared as strings
+		if len(nums) == 0 {
+			nums = values
+		}
+		if len(nums) == 0 {
+			return nil
+		}
+		out := nums[0]
+		for _, num := range nums[1:] {
+			cmp := compareValues(num, out)
+			if (fagg == "min" && cmp < 0) || (fagg == "max" && cmp > 0) {
+				out = num
+			}
+		}
+		if len(nums) > 0 && !isInt {
+			if _, ok := out.(int64); ok {
+				return floatOf(out)
+			}
+		}
+		return out
+	}
+	if len(nums) == 0 {
+		return nil
+	}
+	var sum float64
+	floats := make([]float64, len(nums))
+	for idx, num := range nums {
+		floats[idx] = floatOf(num)
+		sum += floats[idx]
+	}
+	avg := sum / float64(len(floats))
+	switch fagg {
+	case "avg", "mean":
+		return avg
+	case "median":
+		sort.Float64s(floats)
+		mid := len(floats) / 2
+		if len(floats)%2 == 1 {
+			if isInt {
+				return int64(floats[mid])
+			}
+			return floats[mid]
+		}
+		return (floats[mid-1] + floats[mid]) / 2
+	case "stddev":
+		// population standard deviation
+		var sq float64
+		for _, f := range floats {
+			sq += (f - avg) * (f - avg)
+		}
+		return math.Sqrt(sq / float64(len(floats)))
+	}
+	return nil
+}
+
+// helper function to apply aggregators of DAS query to DAS records, every
+// aggregator yields DAS record with function, key and result value, records
+// are optionally grouped by values of given attributes
+func aggregateRecords(records []mongo.DASRecord, aggrs [][]string, groupBy []string) []mongo.DASRecord {
+
+	// defer function profiler
+	defer utils.MeasureTime("dasgoclient/aggregateRecords")
+
+	das := mongo.DASRecord{"primary_key": ""}
+	var generic []map[string]interface{}
+	for _, rec := range records {
+		if recordError(rec) != nil {
+			continue
+		}
+		if v, ok := rec["das"].(mongo.DASRecord); ok && len(generic) == 0 {
+			das = v
+		}
+		generic = append(generic, genericRecord(rec))
+	}
+	generic = distinctRecords(generic)
+	// group DAS records by values of group attributes
+	groups := make(map[string][]map[string]interface{})
+	groupValues := make(map[string][]string)
+	var keys []string
+	for _, rec := range generic {
+		var vals []string
+		for _, attr := range groupBy {
+			vals = append(vals, strings.Join(filteredValues(rec, attr), ","))
+		}
+		key := strings.Join(vals, "\x00")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			groupValues[key] = vals
+		}
+		groups[key] = append(groups[key], rec)
+	}
+	if len(groupBy) == 0 && len(keys) == 0 {
+		// aggregators of empty result, e.g. count of zero records
+		keys = []string{""}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		for idx := range groupBy {
+			if cmp := compareValues(groupValues[keys[i]][idx], groupValues[keys[j]][idx]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	var out []mongo.DASRecord
+	for _, key := range keys {
+		for _, agg := range aggrs {
+			fagg, attr := agg[0], agg[1]
+			var values []interface{}
+			for _, rec := range groups[key] {
+				if !strings.Contains(attr, ".") {
+					// count of DAS records, e.g. count(file)
+					if rec[attr] != nil {
+						values = append(values, attr)
+					}
+					continue
+				}
+				values = append(values, aggregatedValues(rec, attr)...)
+			}
+			rec := mongo.DASRecord{
+				"function": fagg,
+				"key":      attr,
+				"result":   mongo.DASRecord{"value": aggregate(fagg, values)},
+				"das":      das,
+			}
+			if len(groupBy) > 0 {
+				group := mongo.DASRecord{}
+				for idx, attr := range groupBy {
+					group[attr] = groupValues[key][idx]
+				}
+				rec["group"] = group
+			}
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// helper function to split generic DAS records into distinct entities of
+// their primary key, e.g. the same file returned in DBS and Rucio records is
+// aggregated once, attributes of the same entity are merged and the first
+// value of every attribute wins
+func distinctRecords(records []map[string]interface{}) []map[string]interface{} {
+	var out []map[string]interface{}
+	index := make(map[string]int)
+	for _, rec := range records {
+		das, _ := rec["das"].(map[string]interface{})
+		pkey, _ := das["primary_key"].(string)
+		arr := strings.SplitN(pkey, ".", 2)
+		var items []interface{}
+		switch v := rec[arr[0]].(type) {
+		case []interface{}:
+			items = v
+		case map[string]interface{}:
+			items = []interface{}{v}
+		}
+		if len(arr) != 2 || len(items) == 0 {
+			out = append(out, rec)
+			continue
+		}
+		var rest []interface{}
+		for _, item := range items {
+			sub, ok := item.(map[string]interface{})
+			if !ok || sub[arr[1]] == nil {
+				rest = append(rest, item)
+				continue
+			}
+			key := formatValue(sub[arr[1]])
+			if idx, ok := index[key]; ok {
+				entity := out[idx][arr[0]].([]interface{})[0].(map[string]interface{})
+				for k, v := range sub {
+					if _, ok := entity[k]; !ok {
+						entity[k] = v
+					}
+				}
+				continue
+			}
+			entity := make(map[string]interface{}, len(sub))
+			for k, v := range sub {
+				entity[k] = v
+			}
+			index[key] = len(out)
+			out = append(out, withAttribute(rec, arr[0], []interface{}{entity}))
+		}
+		// sub-records without primary key value are kept as they are
+		if len(rest) > 0 {
+			out = append(out, withAttribute(rec, arr[0], rest))
+		}
+	}
+	return out
+}
+
+// helper function to get copy of generic DAS record with given value of
+// its top-level attribute
+func withAttribute(rec map[string]interface{}, attr string, val interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(rec))
+	for k, v := range rec {
+		out[k] = v
+	}
+	out[attr] = val
+	return out
+}
+
+// helper function to get result value of aggregated DAS record
+func aggregatedValue(rec mongo.DASRecord) interface{} {
+	switch res := rec["result"].(type) {
+	case mongo.DASRecord:
+		return res["value"]
+	case map[string]interface{}:
+		return res["value"]
+	}
+	return nil
+}
+
+// helper function to format result value of aggregated DAS record
+func formatAggregate(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "N/A"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return formatValue(val)
+}
+
+// helper function to get group of aggregated DAS record in a form of
+// attribute=value pairs
+func aggregatedGroup(rec mongo.DASRecord, groupBy []string) []string {
+	var out []string
+	group, _ := rec["group"].(mongo.DASRecord)
+	for _, attr := range groupBy {
+		out = append(out, fmt.Sprintf("%s=%v", attr, group[attr]))
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAggregate tests aggregator functions
+func TestAggregate(t *testing.T) {
+	assert := assert.New(t)
+	ints := []interface{}{json.Number("1"), json.Number("4"), json.Number("2"), "abc"}
+	assert.Equal(int64(7), aggregate("sum", ints))
+	assert.Equal(int64(4), aggregate("count", ints))
+	assert.Equal(int64(1), aggregate("min", ints))
+	assert.Equal(int64(4), aggregate("max", ints))
+	assert.Equal(7.0/3, aggregate("avg", ints))
+	assert.Equal(int64(2), aggregate("median", ints))
+	assert.InDelta(1.247219, aggregate("stddev", ints), 1e-6)
+
+	floats := []interface{}{json.Number("1.5"), json.Number("2"), json.Number("10"), json.Number("3")}
+	assert.Equal(16.5, aggregate("sum", floats))
+	assert.Equal(2.5, aggregate("median", floats))
+	assert.Equal(1.5, aggregate("min", floats))
+	assert.Equal(10.0, aggregate("max", floats))
+
+	assert.Equal("a", aggregate("min", []interface{}{"b", "a", "c"}))
+	assert.Equal(int64(0), aggregate("count", nil))
+	assert.Equal(int64(0), aggregate("sum", nil))
+	assert.Nil(aggregate("avg", nil))
+	assert.Nil(aggregate("sum", []interface{}{"a", "b"}))
+	assert.Equal("N/A", formatAggregate(aggregate("sum", []interface{}{"a"})))
+	assert.Equal("N/A", formatAggregate(aggregate("max", nil)))
+	assert.Equal("1.25", formatAggregate(1.25))
+	assert.Equal("2063019163", formatAggregate(int64(2063019163)))
+}
+
+// TestAggregateRecords tests aggregation of DAS records
+func TestAggregateRecords(t *testing.T) {
+	assert := assert.New(t)
+	records := []mongo.DASRecord{
+		fileRecord("/store/a/1.root", 100, "/a/b/c#2"),
+		fileRecord("/store/a/2.root", 200, "/a/b/c#1"),
+		fileRecord("/store/a/3.root", 301, "/a/b/c#2"),
+	}
+	out := aggregateRecords(records, [][]string{{"sum", "file.size"}, {"count", "file"}}, []string{"file.block_name"})
+	assert.Equal(4, len(out))
+	var values, groups []interface{}
+	for _, rec := range out {
+		values = append(values, aggregatedValue(rec))
+		groups = append(groups, rec["group"].(mongo.DASRecord)["file.block_name"])
+	}
+	assert.Equal([]interface{}{int64(200), int64(1), int64(401), int64(2)}, values)
+	assert.Equal([]interface{}{"/a/b/c#1", "/a/b/c#1", "/a/b/c#2", "/a/b/c#2"}, groups)
+	lines := getAggregatedRecords(parsedQuery(t, "file dataset=/a/b/c | sum(file.size), count group by file.block_name"), out, []string{"file.block_name"}, " ")
+	assert.Equal([]string{
+		"file.block_name=/a/b/c#1 sum(file.size): 200 count(file): 1",
+		"file.block_name=/a/b/c#2 sum(file.size): 401 count(file): 2",
+	}, lines)
+
+	out = aggregateRecords(records, [][]string{{"avg", "file.size"}, {"median", "file.size"}}, nil)
+	assert.Equal([]string{"avg(file.size): 200.33333333333334", "median(file.size): 200"}, getAggregatedRecords(parsedQuery(t, "file dataset=/a/b/c | avg(file.size)"), out, nil, " "))
+
+	out = aggregateRecords(nil, [][]string{{"count", "file.name"}}, nil)
+	assert.Equal(int64(0), aggregatedValue(out[0]))
+}
+
+// TestAggregateSubRecords tests that aggregators count identical sub-records
+// of DAS record once and keep equal values of different sub-records
+func TestAggregateSubRecords(t *testing.T) {
+	assert := assert.New(t)
+	run := mongo.DASRecord{
+		"run": []mongo.DASRecord{{"run_number": 1}},
+		"lumi": []mongo.DASRecord{
+			{"number": 1, "events": 10},
+			{"number": 2, "events": 10},
+			{"number": 3, "events": 5},
+		},
+		"das": mongo.DASRecord{"primary_key": "run.run_number"},
+	}
+	out := aggregateRecords([]mongo.DASRecord{run}, [][]string{{"sum", "lumi.events"}, {"count", "lumi.number"}}, nil)
+	assert.Equal(int64(25), aggregatedValue(out[0]))
+	assert.Equal(int64(3), aggregatedValue(out[1]))
+
+	// the same file provided by DBS and Rucio
+	file := mongo.DASRecord{
+		"file": []mongo.DASRecord{
+			{"name": "/store/a/1.root", "size": 100},
+			{"name": "/store/a/1.root", "size": 100},
+		},
+		"das": mongo.DASRecord{"primary_key": "file.name"},
+	}
+	out = aggregateRecords([]mongo.DASRecord{file}, [][]string{{"sum", "file.size"}, {"sum", "file.name"}}, nil)
+	assert.Equal(int64(100), aggregatedValue(out[0]))
+	assert.Nil(aggregatedValue(out[1]))
+
+	// the same file returned in separate DBS and Rucio records is counted
+	// once and its attributes are merged
+	dbs := mongo.DASRecord{
+		"file": []mongo.DASRecord{{"name": "/store/a/1.root", "size": 100, "block_name": "/a/b/c#1"}},
+		"das":  mongo.DASRecord{"primary_key": "file.name", "system": []string{"dbs3"}},
+	}
+	rucio := mongo.DASRecord{
+		"file": []mongo.DASRecord{
+			{"name": "/store/a/1.root", "size": 100, "replicas": 2},
+			{"name": "/store/a/2.root", "size": 50},
+		},
+		"das": mongo.DASRecord{"primary_key": "file.name", "system": []string{"rucio"}},
+	}
+	out = aggregateRecords([]mongo.DASRecord{dbs, rucio}, [][]string{{"sum", "file.size"}, {"count", "file.name"}, {"sum", "file.replicas"}}, nil)
+	assert.Equal(int64(150), aggregatedValue(out[0]))
+	assert.Equal(int64(2), aggregatedValue(out[1]))
+	assert.Equal(int64(2), aggregatedValue(out[2]))
+	out = aggregateRecords([]mongo.DASRecord{dbs, rucio}, [][]string{{"count", "file"}}, []string{"file.block_name"})
+	assert.Equal([]interface{}{int64(1), int64(1)}, []interface{}{aggregatedValue(out[0]), aggregatedValue(out[1])})
+	assert.Equal("", out[0]["group"].(mongo.DASRecord)["file.block_name"])
+	assert.Equal("/a/b/c#1", out[1]["group"].(mongo.DASRecord)["file.block_name"])
+
+	// list values of single sub-record are kept
+	rec := map[string]interface{}{"run": []interface{}{map[string]interface{}{"run_number": []interface{}{1, 1, 2}}}}
+	assert.Equal([]interface{}{1, 1, 2}, aggregatedValues(rec, "run.run_number"))
+	assert.Equal([]interface{}{1, 2}, uniqueValues(rec, "run.run_number"))
+}
+
+// helper function to parse DAS query with pipe
+func parsedQuery(t *testing.T, query string) dasql.DASQuery {
+	dasquery, pipeline, err, _ := parseDASQuery(query, "", []string{"file", "dataset", "block"})
+	assert.Equal(t, "", err)
+	assert.NotEqual(t, 0, len(pipeline.Aggregators))
+	return dasquery
+}
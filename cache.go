@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/utils"
+)
+
+// MapsCache keeps DAS maps which we share across DAS queries in
+// long-running modes and reload them once they expire
+type MapsCache struct {
+	Profile Profile
+	TTL     time.Duration
+	maps    *dasmaps.DASMaps
+	ts      time.Time
+	mutex   sync.Mutex
+}
+
+// Get returns cached DAS maps or load new ones if they are expired
+func (c *MapsCache) Get() *dasmaps.DASMaps {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	hit := c.maps != nil && time.Since(c.ts) < c.TTL
+	dasMetrics.CacheLookup("dasmaps", hit)
+	if !hit {
+		c.maps = loadDASMaps(c.Profile)
+		c.ts = time.Now()
+	}
+	return c.maps
+}
+
+// ClientCache keeps HTTP client which we share across DAS queries to
+// re-use its connections, the client is renewed after given TTL to pick
+// up renewed X509 proxy
+type ClientCache struct {
+	TTL    time.Duration
+	client *http.Client
+	ts     time.Time
+	mutex  sync.Mutex
+}
+
+// Get returns cached HTTP client or creates a new one if it is expired
+func (c *ClientCache) Get() *http.Client {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	hit := c.client != nil && time.Since(c.ts) < c.TTL
+	dasMetrics.CacheLookup("httpclient", hit)
+	if !hit {
+		c.client = newHttpClient()
+		c.ts = time.Now()
+	}
+	return c.client
+}
+
+// shared HTTP client, nil means we create new HTTP client for every DAS query
+var sharedClient *ClientCache
+
+// helper function to create new HTTP client for upstream calls
+func newHttpClient() *http.Client {
+	return metricsClient(traceClient(statsClient(utils.HttpClient())))
+}
+
+// helper function to get HTTP client for upstream calls
+func httpClient() *http.Client {
+	if sharedClient != nil {
+		return sharedClient.Get()
+	}
+	return newHttpClient()
+}
+
+// contextTransport is HTTP transport which binds outgoing HTTP requests to
+// the context, i.e. requests are cancelled along with the context
+type contextTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// helper function to get copy of HTTP client whose requests are cancelled
+// along with given context and carry its values, e.g. span of url fetch
+func contextClient(ctx context.Context, client *http.Client) *http.Client {
+	c := *client
+	if c.Transport == nil {
+		c.Transport = http.DefaultTransport
+	}
+	c.Transport = &contextTransport{base: c.Transport, ctx: ctx}
+	return &c
+}
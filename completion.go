@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/utils"
+)
+
+// list of dasgoclient sub-commands
+var dasCommands = []string{"completion", "config", "diff", "lint", "maps", "serve", "shell"}
+
+// CompletionData represents data we use in shell completion scripts
+type CompletionData struct {
+	Commands  string // dasgoclient sub-commands
+	Maps      string // maps sub-commands
+	Flags     string // dasgoclient flags
+	Keys      string // DAS keys
+	Systems   string // CMS data-services
+	Instances string // DBS instances
+	Pipes     string // DAS filters and aggregators
+}
+
+// helper function to get list of DBS instances from DAS maps
+func dasInstances(dmaps *dasmaps.DASMaps) []string {
+	var out []string
+	for _, rec := range dmaps.Maps() {
+		if vals, ok := rec["instances"].([]interface{}); ok {
+			for _, v := range vals {
+				if inst, ok := v.(string); ok && !utils.InList(inst, out) {
+					out = append(out, inst)
+				}
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// helper function to get list of dasgoclient flags, non-boolean flags are
+// followed by = sign
+func dasFlags() []string {
+	var out []string
+	flag.VisitAll(func(f *flag.Flag) {
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			out = append(out, "-"+f.Name)
+		} else {
+			out = append(out, "-"+f.Name+"=")
+		}
+	})
+	return out
+}
+
+// helper function to collect shell completion data from DAS maps
+func completionData(dmaps *dasmaps.DASMaps) CompletionData {
+	keys := []string{"system", "instance"}
+	for k := range dasKeyServices(dmaps) {
+		for _, key := range strings.Split(k, ",") {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range dmaps.DASKeys() {
+		keys = append(keys, key)
+	}
+	keys = utils.List2Set(keys)
+	sort.Strings(keys)
+	systems := utils.List2Set(dmaps.Services())
+	sort.Strings(systems)
+	var pipes []string
+	for _, f := range dasFilters {
+		pipes = append(pipes, f)
+	}
+	for _, agg := range dasAggregators {
+		pipes = append(pipes, agg+"(")
+	}
+	return CompletionData{
+		Commands:  strings.Join(dasCommands, " "),
+		Maps:      strings.Join(mapsCommands, " "),
+		Flags:     strings.Join(dasFlags(), " "),
+		Keys:      strings.Join(keys, " "),
+		Systems:   strings.Join(systems, " "),
+		Instances: strings.Join(dasInstances(dmaps), " "),
+		Pipes:     strings.Join(pipes, " "),
+	}
+}
+
+// bash completion script, it is also used by zsh via bashcompinit
+var bashCompletion = `# bash completion for dasgoclient
+# generated by: dasgoclient completion bash
+_dasgoclient() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local line="${COMP_LINE:0:$COMP_POINT}"
+    local commands="{{.Commands}}"
+    local flags="{{.Flags}}"
+    local keys="{{.Keys}}"
+    local systems="{{.Systems}}"
+    local instances="{{.Instances}}"
+    local pipes="{{.Pipes}}"
+    local IFS=$' \t\n'
+    if [[ "$line" == *-query=* ]]; then
+        # complete last word of DAS query
+        local query="${line##*-query=}"
+        local word="${query##*[ |(=\"\']}"
+        local before="${query%"$word"}"
+        local words=""
+        case "$before" in
+            *system=) words="$systems" ;;
+            *instance=) words="$instances" ;;
+            *=) words="" ;;
+            *\|*) words="$pipes" ;;
+            *) words="$keys" ;;
+        esac
+        local prefix="${cur%"$word"}"
+        COMPREPLY=( $(compgen -P "$prefix" -W "$words" -- "$word") )
+        return 0
+    fi
+    if [[ $COMP_CWORD -eq 1 && "$cur" != -* ]]; then
+        COMPREPLY=( $(compgen -W "$commands" -- "$cur") )
+    elif [[ "${COMP_WORDS[1]}" == "completion" ]]; then
+        COMPREPLY=( $(compgen -W "bash zsh fish" -- "$cur") )
+    elif [[ $COMP_CWORD -eq 2 && "${COMP_WORDS[1]}" == "maps" && "$cur" != -* ]]; then
+        COMPREPLY=( $(compgen -W "{{.Maps}}" -- "$cur") )
+    elif [[ $COMP_CWORD -eq 2 && "${COMP_WORDS[1]}" == "config" && "$cur" != -* ]]; then
+        COMPREPLY=( $(compgen -W "show" -- "$cur") )
+    else
+        COMPREPLY=( $(compgen -W "$flags" -- "$cur") )
+    fi
+    return 0
+}
+complete -o nospace -F _dasgoclient dasgoclient
+`
+
+// zsh completion script
+var zshCompletion = `#compdef dasgoclient
+# zsh completion for dasgoclient
+# generated by: dasgoclient completion zsh
+autoload -U +X bashcompinit && bashcompinit
+` + bashCompletion
+
+// fish completion script
+var fishCompletion = `# fish completion for dasgoclient
+# generated by: dasgoclient completion fish
+function __dasgoclient_complete
+    set -l token (commandline -ct)
+    set -l keys {{.Keys}}
+    set -l systems {{.Systems}}
+    set -l instances {{.Instances}}
+    set -l pipes {{.Pipes}}
+    if string match -q -- '-query=*' $token
+        # complete last word of DAS query
+        set -l query (string replace -r -- '^-query=' '' $token)
+        set -l word (string replace -r -- '^.*[ |(="\']' '' $query)
+        set -l before (string replace -r -- (string escape --style=regex $word)'$' '' $query)
+        set -l prefix (string replace -r -- (string escape --style=regex $word)'$' '' $token)
+        set -l words $keys
+        if string match -q -r -- 'system=$' $before
+            set words $systems
+        else if string match -q -r -- 'instance=$' $before
+            set words $instances
+        else if string match -q -r -- '=$' $before
+            set words
+        else if string match -q -- '*|*' $before
+            set words $pipes
+        end
+        for w in $words
+            echo $prefix$w
+        end
+        return
+    end
+    if test (count (commandline -opc)) -eq 1; and not string match -q -- '-*' $token
+        printf '%s\n' {{.Commands}}
+    else if contains -- completion (commandline -opc)
+        printf '%s\n' bash zsh fish
+    else if test (count (commandline -opc)) -eq 2; and contains -- maps (commandline -opc); and not string match -q -- '-*' $token
+        printf '%s\n' {{.Maps}}
+    else if test (count (commandline -opc)) -eq 2; and contains -- config (commandline -opc); and not string match -q -- '-*' $token
+        printf '%s\n' show
+    else
+        printf '%s\n' {{.Flags}}
+    end
+end
+complete -c dasgoclient -f -a '(__dasgoclient_complete)'
+`
+
+// helper function to write shell completion script for given shell
+func writeCompletion(w io.Writer, shell string, data CompletionData) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf("unsupported shell '%s', should be one of bash, zsh or fish", shell)
+	}
+	tmpl, err := template.New(shell).Parse(script)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// helper function to write shell completion script based on DAS maps
+func completion(w io.Writer, shell string) error {
+	var dmaps dasmaps.DASMaps
+	dmaps.LoadMapsFromFile()
+	return writeCompletion(w, shell, completionData(&dmaps))
+}
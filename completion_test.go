@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompletion tests generation of shell completion scripts
+func TestCompletion(t *testing.T) {
+	assert := assert.New(t)
+	data := CompletionData{
+		Commands:  "completion serve shell",
+		Flags:     "-json -query=",
+		Keys:      "dataset file",
+		Systems:   "dbs3 rucio",
+		Instances: "prod/global prod/phys03",
+		Pipes:     "grep sum(",
+	}
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var buf bytes.Buffer
+		err := writeCompletion(&buf, shell, data)
+		assert.Nil(err)
+		assert.Contains(buf.String(), "dataset file")
+		assert.Contains(buf.String(), "prod/global prod/phys03")
+		assert.Contains(buf.String(), "_dasgoclient")
+	}
+	var buf bytes.Buffer
+	assert.NotNil(writeCompletion(&buf, "tcsh", data))
+}
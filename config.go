@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// prefix of environment variables which override dasgoclient flags
+var configEnvPrefix = "DASGOCLIENT_"
+
+// Config represents dasgoclient configuration file, e.g.
+//
+//	flags:
+//	  host: https://cmsweb-testbed.cern.ch
+//	  urlRetry: 5
+//	profiles:
+//	  dev:
+//	    host: https://my-vm.cern.ch
+//	    instance: int/global
+//	services:
+//	  dbs3: http://localhost:8989
+type Config struct {
+	File     string                 `yaml:"-"`
+	Flags    map[string]interface{} `yaml:"flags"`
+	Profiles map[string]Profile     `yaml:"profiles"`
+	Services map[string]string      `yaml:"services"`
+}
+
+// ConfigValue represents value of dasgoclient option and its source
+type ConfigValue struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// ConfigProfile represents dasgoclient environment and its source
+type ConfigProfile struct {
+	Profile
+	Source string `json:"source"`
+}
+
+// helper function to get location of dasgoclient configuration file
+func configFile() string {
+	if fname := os.Getenv(configEnvPrefix + "CONFIG"); fname != "" {
+		return fname
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "dasgoclient", "config.yaml")
+}
+
+// helper function to load dasgoclient configuration, missing file
+// represents empty configuration
+func loadConfig(fname string) (*Config, error) {
+	config := &Config{File: fname}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return config, fmt.Errorf("unable to parse %s: %v", fname, err)
+	}
+	config.File = fname
+	return config, nil
+}
+
+// Apply sets dasgoclient flags and environments from configuration file and
+// DASGOCLIENT_* environment variables, it returns source of every flag value
+// which should be updated for flags provided via command line
+func (c *Config) Apply(fs *flag.FlagSet, environ []string) (map[string]string, error) {
+	sources := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		sources[f.Name] = "default"
+	})
+	set := func(name, value, source string) error {
+		f := fs.Lookup(name)
+		if f == nil {
+			return fmt.Errorf("unknown option %s in %s", name, source)
+		}
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value %q of %s in %s: %v", value, name, source, err)
+		}
+		sources[name] = source
+		return nil
+	}
+	for _, name := range sortedKeys(c.Flags) {
+		if err := set(name, fmt.Sprint(c.Flags[name]), c.File); err != nil {
+			return sources, err
+		}
+	}
+	if len(c.Services) > 0 && sources["serviceUrl"] == "default" {
+		var items []string
+		for _, srv := range sortedKeys(c.Services) {
+			items = append(items, fmt.Sprintf("%s=%s", srv, c.Services[srv]))
+		}
+		if err := set("serviceUrl", strings.Join(items, ","), c.File); err != nil {
+			return sources, err
+		}
+	}
+	// flag names are case-sensitive while environment variables are
+	// upper-case, e.g. DASGOCLIENT_URLRETRY sets -urlRetry
+	names := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		names[strings.ToUpper(f.Name)] = f.Name
+	})
+	sort.Strings(environ)
+	for _, item := range environ {
+		arr := strings.SplitN(item, "=", 2)
+		if len(arr) != 2 || !strings.HasPrefix(arr[0], configEnvPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(arr[0], configEnvPrefix)
+		if key == "CONFIG" {
+			continue
+		}
+		name, ok := names[key]
+		if !ok {
+			return sources, fmt.Errorf("unknown option %s in environment %s", strings.ToLower(key), arr[0])
+		}
+		if err := set(name, arr[1], "environment "+arr[0]); err != nil {
+			return sources, err
+		}
+	}
+	for name, p := range c.Profiles {
+		// profile of configuration file may only override some values
+		// of existing environment
+		if base, ok := dasProfiles[name]; ok {
+			if p.Host == "" {
+				p.Host = base.Host
+			}
+			if p.Instance == "" {
+				p.Instance = base.Instance
+			}
+			if p.Services == nil {
+				p.Services = base.Services
+			}
+		}
+		if p.Host == "" || p.Instance == "" {
+			return sources, fmt.Errorf("profile %s in %s should define host and instance", name, c.File)
+		}
+		p.Name = name
+		dasProfiles[name] = p
+	}
+	return sources, nil
+}
+
+// helper function to update sources of flags provided via command line
+func commandLineSources(fs *flag.FlagSet, sources map[string]string) {
+	fs.Visit(func(f *flag.Flag) {
+		sources[f.Name] = "command line"
+	})
+}
+
+// helper function to show effective dasgoclient configuration
+func showConfig(w io.Writer, fs *flag.FlagSet, config *Config, sources map[string]string, jsonout bool) {
+	var values []ConfigValue
+	fs.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if f.Name == "token" && value != "" {
+			// token option contains either token file or token itself
+			if _, err := os.Stat(value); err != nil {
+				value = "<hidden>"
+			}
+		}
+		values = append(values, ConfigValue{Name: f.Name, Value: value, Source: sources[f.Name]})
+	})
+	var profiles []ConfigProfile
+	for _, name := range sortedKeys(dasProfiles) {
+		source := "default"
+		if _, ok := config.Profiles[name]; ok {
+			source = config.File
+		}
+		profiles = append(profiles, ConfigProfile{Profile: dasProfiles[name], Source: source})
+	}
+	if jsonout {
+		printJSON(map[string]interface{}{"file": config.File, "options": values, "profiles": profiles})
+		return
+	}
+	fmt.Fprintf(w, "# configuration file: %s\n", config.File)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, v := range values {
+		fmt.Fprintf(tw, "%s\t%q\t# %s\n", v.Name, v.Value, v.Source)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "# environments:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, p := range profiles {
+		fmt.Fprintf(tw, "%s\t# %s\n", p.Profile, p.Source)
+	}
+	tw.Flush()
+}
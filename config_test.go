@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfig tests dasgoclient configuration file and environment overrides
+func TestConfig(t *testing.T) {
+	assert := assert.New(t)
+	fname := filepath.Join(t.TempDir(), "config.yaml")
+	data := `flags:
+  host: https://cmsweb-testbed.cern.ch
+  urlRetry: 5
+  sep: ","
+profiles:
+  dev:
+    host: https://my-vm.cern.ch
+    instance: prod/phys03
+  prod:
+    instance: prod/phys01
+services:
+  dbs3: http://localhost:8989
+`
+	assert.Nil(os.WriteFile(fname, []byte(data), 0644))
+	config, err := loadConfig(fname)
+	assert.Nil(err)
+
+	// keep environments table intact for other tests
+	profiles := make(map[string]Profile)
+	for k, v := range dasProfiles {
+		profiles[k] = v
+	}
+	defer func() { dasProfiles = profiles }()
+
+	fs := flag.NewFlagSet("dasgoclient", flag.ContinueOnError)
+	host := fs.String("host", "https://cmsweb.cern.ch", "")
+	urlRetry := fs.Int("urlRetry", 3, "")
+	sep := fs.String("sep", " ", "")
+	timeout := fs.Int("timeout", 0, "")
+	serviceUrl := fs.String("serviceUrl", "", "")
+	fs.String("token", "", "")
+	environ := []string{"DASGOCLIENT_URLRETRY=7", "DASGOCLIENT_CONFIG=" + fname, "HOME=/tmp"}
+	sources, err := config.Apply(fs, environ)
+	assert.Nil(err)
+	assert.Nil(fs.Parse([]string{"-timeout=10", "-token=secret"}))
+	commandLineSources(fs, sources)
+
+	assert.Equal("https://cmsweb-testbed.cern.ch", *host)
+	assert.Equal(7, *urlRetry)
+	assert.Equal(",", *sep)
+	assert.Equal(10, *timeout)
+	assert.Equal("dbs3=http://localhost:8989", *serviceUrl)
+	assert.Equal(fname, sources["host"])
+	assert.Equal("environment DASGOCLIENT_URLRETRY", sources["urlRetry"])
+	assert.Equal("command line", sources["timeout"])
+	assert.Equal(fname, sources["serviceUrl"])
+
+	assert.Equal(Profile{Name: "dev", Host: "https://my-vm.cern.ch", Instance: "prod/phys03"}, dasProfiles["dev"])
+	assert.Equal("https://cmsweb.cern.ch", dasProfiles["prod"].Host)
+	assert.Equal("prod/phys01", dasProfiles["prod"].Instance)
+
+	var buf bytes.Buffer
+	showConfig(&buf, fs, config, sources, false)
+	out := buf.String()
+	assert.Contains(out, "# configuration file: "+fname)
+	assert.Contains(out, "# environment DASGOCLIENT_URLRETRY")
+	assert.Contains(out, "<hidden>")
+	assert.NotContains(out, "secret")
+
+	_, err = config.Apply(fs, []string{"DASGOCLIENT_LISTEN=:8217"})
+	assert.NotNil(err)
+	_, err = config.Apply(fs, []string{"DASGOCLIENT_TIMEOUT=abc"})
+	assert.NotNil(err)
+
+	// missing configuration file is empty configuration
+	config, err = loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Nil(err)
+	assert.Equal(0, len(config.Flags))
+	assert.Nil(os.WriteFile(fname, []byte("flags: [1, 2"), 0644))
+	_, err = loadConfig(fname)
+	assert.NotNil(err)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/utils"
+)
+
+// CrossCheckAttribute represents attribute of DAS record which has
+// different values in CMS data-services
+type CrossCheckAttribute struct {
+	Name   string            `json:"name"`
+	Values map[string]string `json:"values"`
+}
+
+// CrossCheckRecord represents discrepancy of DAS record among CMS
+// data-services, i.e. record missing in some of them or its attributes
+// with different values
+type CrossCheckRecord struct {
+	Key        string                `json:"key"`
+	Missing    []string              `json:"missing,omitempty"`
+	Attributes []CrossCheckAttribute `json:"attributes,omitempty"`
+}
+
+// CrossCheckReplicas represents check of replicas of DBS blocks in Rucio
+type CrossCheckReplicas struct {
+	Blocks  int      `json:"blocks"`
+	Missing []string `json:"missing"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// CrossCheckReport represents outcome of cross-check of DAS query among
+// CMS data-services which can answer it
+type CrossCheckReport struct {
+	Query         string              `json:"query"`
+	Services      []string            `json:"services"`
+	Records       map[string]int      `json:"records"`
+	Errors        map[string]string   `json:"errors,omitempty"`
+	Consistent    int                 `json:"consistent"`
+	Discrepancies []CrossCheckRecord  `json:"discrepancies"`
+	Replicas      *CrossCheckReplicas `json:"replicas,omitempty"`
+}
+
+// helper function to find CMS data-services which can answer DAS query
+func crossCheckServices(dasquery dasql.DASQuery, dmaps *dasmaps.DASMaps) []string {
+	var out []string
+	for _, dmap := range dmaps.FindServices(dasquery) {
+		if system, ok := dmap["system"].(string); ok && !utils.InList(system, out) {
+			out = append(out, system)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// helper function to join DAS records of CMS data-services on their primary
+// key values and find discrepancies among them, attributes are compared
+// only if they are provided by all services which have given record
+func crossCheckRecords(report *CrossCheckReport, records map[string][]map[string]interface{}) {
+	index := make(map[string]map[string]map[string][]string)
+	var keys []string
+	for _, srv := range report.Services {
+		if _, ok := report.Errors[srv]; ok {
+			continue
+		}
+		index[srv] = indexRecords(records[srv])
+		for key := range index[srv] {
+			keys = append(keys, key)
+		}
+	}
+	keys = utils.List2Set(keys)
+	sort.Strings(keys)
+	for _, key := range keys {
+		rec := CrossCheckRecord{Key: key}
+		var found []string
+		for _, srv := range sortedKeys(index) {
+			if _, ok := index[srv][key]; ok {
+				found = append(found, srv)
+			} else {
+				rec.Missing = append(rec.Missing, srv)
+			}
+		}
+		var names []string
+		for name := range index[found[0]][key] {
+			if strings.HasPrefix(name, "das.") || name == "qhash" {
+				continue
+			}
+			common := true
+			for _, srv := range found[1:] {
+				if _, ok := index[srv][key][name]; !ok {
+					common = false
+				}
+			}
+			if common {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			values := make(map[string]string)
+			for _, srv := range found {
+				values[srv] = strings.Join(index[srv][key][name], ", ")
+			}
+			if len(utils.List2Set(mapValues(values))) > 1 {
+				rec.Attributes = append(rec.Attributes, CrossCheckAttribute{Name: name, Values: values})
+			}
+		}
+		if len(rec.Missing) > 0 || len(rec.Attributes) > 0 {
+			report.Discrepancies = append(report.Discrepancies, rec)
+		} else {
+			report.Consistent += 1
+		}
+	}
+}
+
+// helper function to check replicas of given DBS blocks, replicas function
+// returns number of replicas of given block
+func crossCheckReplicas(blocks []string, replicas func(block string) (int, error)) *CrossCheckReplicas {
+	out := &CrossCheckReplicas{Blocks: len(blocks), Missing: []string{}}
+	for _, block := range blocks {
+		nrep, err := replicas(block)
+		if err != nil {
+			out.Error = fmt.Sprintf("%s: %v", block, err)
+			return out
+		}
+		if nrep == 0 {
+			out.Missing = append(out.Missing, block)
+		}
+	}
+	return out
+}
+
+// helper function to get values of given map
+func mapValues(m map[string]string) []string {
+	var out []string
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
+
+// helper function to print cross-check report
+func (r CrossCheckReport) Print(w io.Writer) {
+	var srvs []string
+	for _, srv := range r.Services {
+		if err, ok := r.Errors[srv]; ok {
+			srvs = append(srvs, fmt.Sprintf("%s (error: %s)", srv, err))
+		} else {
+			srvs = append(srvs, fmt.Sprintf("%s (%d records)", srv, r.Records[srv]))
+		}
+	}
+	fmt.Fprintf(w, "cross-check of %s: %s\n", r.Query, strings.Join(srvs, ", "))
+	for _, rec := range r.Discrepancies {
+		if len(rec.Missing) > 0 {
+			fmt.Fprintf(w, "- %s: missing in %s\n", rec.Key, strings.Join(rec.Missing, ", "))
+		}
+		for _, attr := range rec.Attributes {
+			var vals []string
+			for _, srv := range sortedKeys(attr.Values) {
+				vals = append(vals, fmt.Sprintf("%s=%s", srv, attr.Values[srv]))
+			}
+			fmt.Fprintf(w, "~ %s: %s %s\n", rec.Key, attr.Name, strings.Join(vals, " "))
+		}
+	}
+	fmt.Fprintf(w, "%d consistent, %d discrepancies\n", r.Consistent, len(r.Discrepancies))
+	if r.Replicas != nil {
+		for _, block := range r.Replicas.Missing {
+			fmt.Fprintf(w, "- %s: no replicas in rucio\n", block)
+		}
+		if r.Replicas.Error != "" {
+			fmt.Fprintf(w, "replicas check error: %s\n", r.Replicas.Error)
+		}
+		fmt.Fprintf(w, "%d blocks, %d without replicas\n", r.Replicas.Blocks, len(r.Replicas.Missing))
+	}
+}
+
+// helper function to run DAS query against every CMS data-service which
+// can answer it and report discrepancies among their results, DBS blocks
+// of block queries are also checked for their replicas in Rucio, it returns
+// DAS exit code
+func crossCheck(query string, opts Options, dmaps *dasmaps.DASMaps) int {
+	dasquery, _, err, _ := parseDASQuery(query, opts.Profile.Instance, dmaps.DASKeys())
+	if err != "" {
+		fmt.Println("ERROR: das parser error:", err)
+		return utils.DASParserError
+	}
+	if dasquery.System != "" {
+		fmt.Println("ERROR: -crosscheck option can't be used with system key of DAS query")
+		return utils.DASQueryError
+	}
+	report := CrossCheckReport{
+		Query:    query,
+		Services: crossCheckServices(dasquery, dmaps),
+		Records:  make(map[string]int),
+		Errors:   make(map[string]string),
+
+		Discrepancies: []CrossCheckRecord{},
+	}
+	blockQuery := len(dasquery.Fields) == 1 && dasquery.Fields[0] == "block"
+	if len(report.Services) < 2 && !(blockQuery && utils.InList("dbs3", report.Services)) {
+		fmt.Printf("ERROR: cross-check requires at least two CMS data-services, found %v\n", report.Services)
+		return utils.DASQueryError
+	}
+	records := make(map[string][]map[string]interface{})
+	ecode := 0
+	for _, srv := range report.Services {
+		recs, code, msg := queryRecords(addCondition(query, "system="+srv), opts, dmaps)
+		if code != 0 {
+			report.Errors[srv] = msg
+			ecode = code
+			continue
+		}
+		records[srv] = recs
+		report.Records[srv] = len(recs)
+	}
+	if len(report.Services)-len(report.Errors) > 1 {
+		crossCheckRecords(&report, records)
+	}
+	// blocks known to DBS should have replicas in Rucio
+	if blocks, ok := records["dbs3"]; ok && blockQuery {
+		report.Replicas = crossCheckReplicas(sortedKeys(indexRecords(blocks)), func(block string) (int, error) {
+			recs, code, msg := queryRecords(fmt.Sprintf("site block=%s system=rucio", block), opts, dmaps)
+			if code != 0 {
+				ecode = code
+				return 0, errors.New(msg)
+			}
+			return len(recs), nil
+		})
+	}
+	if opts.JSON {
+		printJSON(report)
+	} else {
+		report.Print(os.Stdout)
+	}
+	return ecode
+}
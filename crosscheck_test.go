@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCrossCheckServices tests look-up of CMS data-services for DAS query
+func TestCrossCheckServices(t *testing.T) {
+	assert := assert.New(t)
+	var dmaps dasmaps.DASMaps
+	dmaps.ReadMapFile("testdata/das_maps.js")
+	dasquery, err, _ := dasql.Parse("site site=T1_CH_CERN", "prod/global", dmaps.DASKeys())
+	assert.Equal("", err)
+	assert.Equal([]string{"cric", "rucio"}, crossCheckServices(dasquery, &dmaps))
+	dasquery, err, _ = dasql.Parse("block dataset=/A/B-v1/RAW", "prod/global", dmaps.DASKeys())
+	assert.Equal("", err)
+	assert.Equal([]string{"dbs3", "rucio"}, crossCheckServices(dasquery, &dmaps))
+
+	// DAS queries with system key can't be cross-checked
+	assert.Equal(utils.DASQueryError, crossCheck("block dataset=/a/b/c system=dbs3", Options{}, &dmaps))
+	assert.Equal(utils.DASParserError, crossCheck("block datset=/a/b/c", Options{}, &dmaps))
+}
+
+// TestCrossCheckReplicas tests check of replicas of DBS blocks in Rucio
+func TestCrossCheckReplicas(t *testing.T) {
+	assert := assert.New(t)
+	blocks, err := readRecords(strings.NewReader(`[
+{"das":{"primary_key":"block.name","services":["dbs3:blocks"]},"block":[{"name":"/a/b/c#1","size":10}]},
+{"das":{"primary_key":"block.name","services":["dbs3:blocks"]},"block":[{"name":"/a/b/c#2","size":20}]},
+{"das":{"primary_key":"block.name","services":["dbs3:blocks"]},"block":[{"name":"/a/b/c#3","size":30}]}
+]`))
+	assert.Nil(err)
+	replicas := map[string]int{"/a/b/c#1": 2, "/a/b/c#3": 1}
+	var queried []string
+	report := CrossCheckReport{Query: "block dataset=/a/b/c", Discrepancies: []CrossCheckRecord{}}
+	report.Replicas = crossCheckReplicas(sortedKeys(indexRecords(blocks)), func(block string) (int, error) {
+		queried = append(queried, block)
+		return replicas[block], nil
+	})
+	assert.Equal([]string{"/a/b/c#1", "/a/b/c#2", "/a/b/c#3"}, queried)
+	assert.Equal(&CrossCheckReplicas{Blocks: 3, Missing: []string{"/a/b/c#2"}}, report.Replicas)
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+	assert.Contains(buf.String(), "- /a/b/c#2: no replicas in rucio\n3 blocks, 1 without replicas\n")
+	data, err := json.Marshal(report)
+	assert.Nil(err)
+	assert.Contains(string(data), `"replicas":{"blocks":3,"missing":["/a/b/c#2"]}`)
+
+	// replicas check stops at first error of Rucio
+	res := crossCheckReplicas([]string{"/a/b/c#1", "/a/b/c#2"}, func(block string) (int, error) {
+		return 0, errors.New("Rucio upstream error")
+	})
+	assert.Equal(&CrossCheckReplicas{Blocks: 2, Missing: []string{}, Error: "/a/b/c#1: Rucio upstream error"}, res)
+}
+
+// TestCrossCheckRecords tests cross-check of DAS records of CMS data-services
+func TestCrossCheckRecords(t *testing.T) {
+	assert := assert.New(t)
+	dbs, err := readRecords(strings.NewReader(`[
+{"das":{"primary_key":"file.name","services":["dbs3:files"]},"file":[{"name":"/store/a.root","size":10,"adler32":"abc"}]},
+{"das":{"primary_key":"file.name","services":["dbs3:files"]},"file":[{"name":"/store/b.root","size":20,"adler32":"def"}]},
+{"das":{"primary_key":"file.name","services":["dbs3:files"]},"file":[{"name":"/store/c.root","size":30,"nevents":5}]}
+]`))
+	assert.Nil(err)
+	rucio, err := readRecords(strings.NewReader(`[
+{"das":{"primary_key":"file.name","services":["rucio:files"]},"file":[{"name":"/store/a.root","size":10,"adler32":"abc"}]},
+{"das":{"primary_key":"file.name","services":["rucio:files"]},"file":[{"name":"/store/c.root","size":31}]}
+]`))
+	assert.Nil(err)
+	report := CrossCheckReport{
+		Services:      []string{"cric", "dbs3", "rucio"},
+		Errors:        map[string]string{"cric": "no APIs found to answer your query"},
+		Discrepancies: []CrossCheckRecord{},
+	}
+	records := map[string][]map[string]interface{}{"dbs3": dbs, "rucio": rucio}
+	crossCheckRecords(&report, records)
+	assert.Equal(1, report.Consistent)
+	expect := []CrossCheckRecord{
+		{Key: "/store/b.root", Missing: []string{"rucio"}},
+		{Key: "/store/c.root", Attributes: []CrossCheckAttribute{
+			{Name: "file.size", Values: map[string]string{"dbs3": "30", "rucio": "31"}},
+		}},
+	}
+	assert.Equal(expect, report.Discrepancies)
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+	out := buf.String()
+	assert.Contains(out, "cric (error: no APIs found to answer your query)")
+	assert.Contains(out, "- /store/b.root: missing in rucio")
+	assert.Contains(out, "~ /store/c.root: file.size dbs3=30 rucio=31")
+	assert.Contains(out, "1 consistent, 2 discrepancies")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/utils"
+)
+
+// DASClientResponse represents das_client.py style output of DAS query
+// (-format=json option), status is either ok or fail and ecode is DAS exit
+// code along with its error message
+type DASClientResponse struct {
+	Status     string            `json:"status"`
+	ECode      int               `json:"ecode"`
+	Error      string            `json:"error,omitempty"`
+	MongoQuery dasql.DASQuery    `json:"mongo_query"`
+	NResults   int               `json:"nresults"`
+	Timestamp  int64             `json:"timestamp"`
+	CTime      int64             `json:"ctime"`
+	Data       []json.RawMessage `json:"data"`
+	Stats      *QueryStats       `json:"stats,omitempty"`
+}
+
+// helper function to select JSON records starting at idx up to limit index
+func selectRecords(records []string, idx, limit int) []string {
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(records) {
+		return nil
+	}
+	if limit > idx && limit < len(records) {
+		return records[idx:limit]
+	}
+	return records[idx:]
+}
+
+// helper function to write list of JSON records
+func writeJSONRecords(w io.Writer, records []string) {
+	fmt.Fprintln(w, "[")
+	for idx, rec := range records {
+		if idx < len(records)-1 {
+			fmt.Fprintln(w, rec, ",")
+		} else {
+			fmt.Fprintln(w, rec)
+		}
+	}
+	fmt.Fprintln(w, "]")
+}
+
+// helper function to write das_client.py style output of DAS query
+// with given JSON records, it returns DAS exit code and marshalling error
+// if any
+func writeDASClient(w io.Writer, res Result, records []string) (int, error) {
+	resp := DASClientResponse{
+		Status:     "ok",
+		ECode:      res.ECode,
+		Error:      res.Error,
+		MongoQuery: res.Query,
+		NResults:   len(res.Records),
+		Timestamp:  time.Now().Unix(),
+		CTime:      time.Now().Unix() - res.Start.Unix(),
+		Data:       []json.RawMessage{},
+	}
+	if res.Message != "" {
+		resp.Error = strings.TrimPrefix(res.Message, "ERROR: ")
+	}
+	if resp.ECode != 0 {
+		resp.Status = "fail"
+	}
+	for _, rec := range records {
+		resp.Data = append(resp.Data, json.RawMessage(rec))
+	}
+	if queryStats != nil {
+		queryStats.Finalize(len(records))
+		resp.Stats = queryStats
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		err = fmt.Errorf("unable to marshal DAS records: %v", err)
+		resp = DASClientResponse{
+			Status:     "fail",
+			ECode:      utils.DASServerError,
+			Error:      err.Error(),
+			MongoQuery: res.Query,
+			Data:       []json.RawMessage{},
+		}
+		data, _ = json.Marshal(resp)
+	}
+	fmt.Fprintln(w, string(data))
+	return resp.ECode, err
+}
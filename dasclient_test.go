@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to get JSON type of decoded value
+func jsonType(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// helper function to decode single JSON document, it fails if output
+// contains anything else
+func decodeOutput(t *testing.T, data []byte) map[string]interface{} {
+	var out map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	assert.Nil(t, decoder.Decode(&out))
+	var extra interface{}
+	assert.Equal(t, io.EOF, decoder.Decode(&extra), "output contains extra data")
+	return out
+}
+
+// TestDASClientWireFormat tests documented attributes of -format=json output
+func TestDASClientWireFormat(t *testing.T) {
+	assert := assert.New(t)
+	dasquery, _, msg, _ := parseDASQuery("file dataset=/a/b/c", "prod/global", []string{"file", "dataset"})
+	assert.Equal("", msg)
+	records := []mongo.DASRecord{fileRecord("/store/a.root", 100, "/a/b/c#1"), fileRecord("/store/b.root", 200, "/a/b/c#1")}
+	res := Result{Query: dasquery, Records: records}
+	res.Query.Detail = true
+	var buf bytes.Buffer
+	assert.Equal(0, writeResult(&buf, res, Options{Format: "json"}))
+	assert.Equal(byte('\n'), buf.Bytes()[buf.Len()-1])
+	out := decodeOutput(t, buf.Bytes())
+
+	types := make(map[string]string)
+	for key, val := range out {
+		types[key] = jsonType(val)
+	}
+	assert.Equal(map[string]string{
+		"status":      "string",
+		"ecode":       "number",
+		"mongo_query": "object",
+		"nresults":    "number",
+		"timestamp":   "number",
+		"ctime":       "number",
+		"data":        "array",
+	}, types)
+	assert.Equal("ok", out["status"])
+	assert.Equal(float64(0), out["ecode"])
+	assert.Equal(float64(2), out["nresults"])
+
+	// data holds DAS records as they are
+	var expect []interface{}
+	for _, rec := range records {
+		data, err := json.Marshal(rec)
+		assert.Nil(err)
+		var val interface{}
+		assert.Nil(json.Unmarshal(data, &val))
+		expect = append(expect, val)
+	}
+	assert.Equal(expect, out["data"])
+
+	// mongo_query is DAS query representation of DAS server
+	data, err := json.Marshal(res.Query)
+	assert.Nil(err)
+	var query map[string]interface{}
+	assert.Nil(json.Unmarshal(data, &query))
+	assert.Equal(query, out["mongo_query"])
+	mongoQuery := out["mongo_query"].(map[string]interface{})
+	assert.Equal([]interface{}{"file"}, mongoQuery["fields"])
+	assert.Equal(map[string]interface{}{"dataset": "/a/b/c"}, mongoQuery["spec"])
+	assert.Equal("prod/global", mongoQuery["instance"])
+}
+
+// TestDASClientErrors tests -format=json output of failed DAS queries
+func TestDASClientErrors(t *testing.T) {
+	assert := assert.New(t)
+	res := Result{
+		ECode:   utils.DASParserError,
+		Message: "ERROR: das parser error: unable to parse DAS query",
+		Fatal:   true,
+	}
+	var buf bytes.Buffer
+	assert.Equal(utils.DASParserError, writeResult(&buf, res, Options{Format: "json"}))
+	out := decodeOutput(t, buf.Bytes())
+	assert.Equal("fail", out["status"])
+	assert.Equal(float64(utils.DASParserError), out["ecode"])
+	assert.Equal("das parser error: unable to parse DAS query", out["error"])
+	assert.Equal([]interface{}{}, out["data"])
+
+	// errors of CMS data-services are reported along with DAS records
+	res = Result{
+		Records: []mongo.DASRecord{fileRecord("/store/a.root", 100, "/a/b/c#1"), fileRecord("/store/b.root", 200, "/a/b/c#1")},
+		ECode:   utils.DBSError,
+		Error:   "DBS upstream error",
+	}
+	res.Query.Detail = true
+	buf.Reset()
+	assert.Equal(utils.DBSError, writeResult(&buf, res, Options{Format: "json", Idx: 1}))
+	out = decodeOutput(t, buf.Bytes())
+	assert.Equal("fail", out["status"])
+	assert.Equal(float64(utils.DBSError), out["ecode"])
+	assert.Equal("DBS upstream error", out["error"])
+	assert.Equal(float64(2), out["nresults"])
+	assert.Equal(1, len(out["data"].([]interface{})))
+
+	assert.Equal([]string{"b", "c"}, selectRecords([]string{"a", "b", "c", "d"}, 1, 3))
+	assert.Equal([]string{"b", "c", "d"}, selectRecords([]string{"a", "b", "c", "d"}, 1, 0))
+	assert.Equal(0, len(selectRecords([]string{"a"}, 1, 0)))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+)
+
+// DASKeyInfo represents DAS key, CMS data-services which provide it and
+// its attributes
+type DASKeyInfo struct {
+	Key           string   `json:"key"`
+	Services      []string `json:"services"`
+	DefaultSystem []string `json:"default_system,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	Attributes    []string `json:"attributes"`
+}
+
+// DASExample represents example of DAS query
+type DASExample struct {
+	Description string `json:"description,omitempty"`
+	Query       string `json:"query"`
+}
+
+// DASExamples represents category of DAS query examples
+type DASExamples struct {
+	Category string       `json:"category"`
+	Examples []DASExample `json:"examples"`
+}
+
+// helper function to print given object in JSON data-format
+func printJSON(obj interface{}) {
+	data, err := json.MarshalIndent(obj, "", "   ")
+	if err != nil {
+		fmt.Println("ERROR: unable to marshal JSON", err)
+		os.Exit(utils.DASServerError)
+	}
+	fmt.Println(string(data))
+}
+
+// helper function to convert DAS map entry into a map, DAS maps loaded
+// from a file contain generic JSON maps rather than DAS records
+func mapRecord(v interface{}) (map[string]interface{}, bool) {
+	switch r := v.(type) {
+	case mongo.DASRecord:
+		return r, true
+	case map[string]interface{}:
+		return r, true
+	}
+	return nil, false
+}
+
+// helper function to get presentation of DAS keys from DAS maps, it returns
+// DAS key attributes and description of every DAS key
+func dasPresentation(dmaps *dasmaps.DASMaps) (map[string][]string, map[string]string) {
+	attrs := make(map[string][]string)
+	descs := make(map[string]string)
+	for _, rec := range dmaps.Maps() {
+		if rec["type"] != "presentation" {
+			continue
+		}
+		prec, ok := mapRecord(rec["presentation"])
+		if !ok {
+			continue
+		}
+		for key, rows := range prec {
+			items, ok := rows.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, item := range items {
+				row, ok := mapRecord(item)
+				if !ok {
+					continue
+				}
+				if attr, ok := row["das"].(string); ok && !utils.InList(attr, attrs[key]) {
+					attrs[key] = append(attrs[key], attr)
+				}
+				if desc, ok := row["description"].(string); ok && descs[key] == "" {
+					descs[key] = desc
+				}
+			}
+		}
+	}
+	return attrs, descs
+}
+
+// helper function to get information about supported DAS keys
+func dasKeysInfo(dmaps *dasmaps.DASMaps) []DASKeyInfo {
+	keys := dasKeyServices(dmaps)
+	keyMap := DASKeyMap()
+	attrs, descs := dasPresentation(dmaps)
+	var out []DASKeyInfo
+	for _, k := range sortedKeys(keys) {
+		srvs := []string{}
+		for _, srv := range utils.List2Set(keys[k]) {
+			srvs = append(srvs, srv)
+		}
+		sort.Strings(srvs)
+		info := DASKeyInfo{Key: k, Services: srvs, DefaultSystem: keyMap[k], Description: descs[k]}
+		info.Attributes = attrs[k]
+		if info.Attributes == nil {
+			info.Attributes = []string{}
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// helper function to parse DAS examples of given category, comment lines
+// describe DAS queries which follow them
+func parseExamples(category, text string) DASExamples {
+	out := DASExamples{Category: category, Examples: []DASExample{}}
+	var desc string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			desc = strings.TrimSpace(strings.TrimLeft(line, "#"))
+			continue
+		}
+		out.Examples = append(out.Examples, DASExample{Description: desc, Query: line})
+	}
+	return out
+}
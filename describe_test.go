@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseExamples tests parsing of DAS query examples
+func TestParseExamples(t *testing.T) {
+	assert := assert.New(t)
+	text := "# find file info\nfile=/a.root\n\n# find files\nfile dataset=/a/b/c\nfile block=/a/b/c#1\n"
+	res := parseExamples("file", text)
+	assert.Equal("file", res.Category)
+	assert.Equal(3, len(res.Examples))
+	assert.Equal(DASExample{Description: "find file info", Query: "file=/a.root"}, res.Examples[0])
+	assert.Equal("find files", res.Examples[2].Description)
+}
+
+// TestDASKeysInfo tests DAS keys information obtained from DAS maps
+func TestDASKeysInfo(t *testing.T) {
+	assert := assert.New(t)
+	maps := `{"hash":"1","type":"service","system":"dbs3","services":"","urn":"files","lookup":"file","das_map":[{"das_key":"file","rec_key":"file.name"}]}
+{"hash":"2","type":"service","system":"rucio","services":"","urn":"file4dataset","lookup":"file","das_map":[{"das_key":"file","rec_key":"file.name"}]}
+{"hash":"3","type":"presentation","presentation":{"file":[{"das":"file.name","description":"is a file"},{"das":"file.size"}]}}
+`
+	fname := filepath.Join(t.TempDir(), "das_maps.js")
+	assert.Nil(os.WriteFile(fname, []byte(maps), 0644))
+	var dmaps dasmaps.DASMaps
+	dmaps.ReadMapFile(fname)
+
+	info := dasKeysInfo(&dmaps)
+	assert.Equal(1, len(info))
+	assert.Equal("file", info[0].Key)
+	assert.Equal([]string{"dbs3", "rucio"}, info[0].Services)
+	assert.Equal([]string{"dbs3", "rucio"}, info[0].DefaultSystem)
+	assert.Equal([]string{"file.name", "file.size"}, info[0].Attributes)
+	assert.Equal("is a file", info[0].Description)
+
+	data, err := json.Marshal(dasExitCodes[0])
+	assert.Nil(err)
+	assert.Contains(string(data), `"code":1,"name":"DAS error","description":`)
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/utils"
+)
+
+// DAS record attributes which are not compared, they change with every
+// DAS query or represent compared DBS instance
+var diffIgnored = []string{"qhash", "das.expire", "das.ts", "das.instance"}
+
+// AttributeChange represents attribute of DAS record with different values
+type AttributeChange struct {
+	Name string `json:"name"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+// RecordChange represents DAS record which differs between two results
+type RecordChange struct {
+	Key        string            `json:"key"`
+	Change     string            `json:"change"`
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+// DiffReport represents differences between two sets of DAS records
+type DiffReport struct {
+	A         string         `json:"a"`
+	B         string         `json:"b"`
+	RecordsA  int            `json:"records_a"`
+	RecordsB  int            `json:"records_b"`
+	Added     int            `json:"added"`
+	Removed   int            `json:"removed"`
+	Changed   int            `json:"changed"`
+	Unchanged int            `json:"unchanged"`
+	Changes   []RecordChange `json:"changes"`
+}
+
+// helper function to normalize DAS record, i.e. convert it to generic JSON
+// map and remove attributes which should not be compared
+func normalizeRecord(rec map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	data, err := json.Marshal(rec)
+	if err != nil || json.Unmarshal(data, &out) != nil {
+		return rec
+	}
+	for _, attr := range diffIgnored {
+		keys := strings.Split(attr, ".")
+		m := out
+		for _, key := range keys[:len(keys)-1] {
+			if m, _ = m[key].(map[string]interface{}); m == nil {
+				break
+			}
+		}
+		if m != nil {
+			delete(m, keys[len(keys)-1])
+		}
+	}
+	return out
+}
+
+// helper function to get primary key value of normalized DAS record, e.g.
+// dataset name for dataset.name primary key
+func primaryValue(rec map[string]interface{}) string {
+	if das, ok := rec["das"].(map[string]interface{}); ok {
+		if pkey, ok := das["primary_key"].(string); ok && pkey != "" {
+			arr := strings.SplitN(pkey, ".", 2)
+			var items []interface{}
+			switch v := rec[arr[0]].(type) {
+			case []interface{}:
+				items = v
+			case map[string]interface{}:
+				items = []interface{}{v}
+			}
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok && len(arr) == 2 {
+					if val := m[arr[1]]; val != nil {
+						return fmt.Sprint(val)
+					}
+				}
+			}
+		}
+	}
+	// records without primary key are matched by their content
+	data, _ := json.Marshal(rec)
+	return string(data)
+}
+
+// helper function to flatten DAS record into dotted attributes and their
+// values, values of lists are accumulated
+func flattenRecord(prefix string, val interface{}, out map[string][]string) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			flattenRecord(name, item, out)
+		}
+	case []interface{}:
+		for _, item := range v {
+			flattenRecord(prefix, item, out)
+		}
+	case string:
+		out[prefix] = append(out[prefix], v)
+	default:
+		data, _ := json.Marshal(v)
+		out[prefix] = append(out[prefix], string(data))
+	}
+}
+
+// helper function to index DAS records by their primary key values, records
+// with the same primary key value, e.g. from different CMS data-services,
+// are merged together
+func indexRecords(records []map[string]interface{}) map[string]map[string][]string {
+	out := make(map[string]map[string][]string)
+	for _, rec := range records {
+		rec = normalizeRecord(rec)
+		key := primaryValue(rec)
+		if _, ok := out[key]; !ok {
+			out[key] = make(map[string][]string)
+		}
+		flattenRecord("", rec, out[key])
+	}
+	for _, attrs := range out {
+		for name, vals := range attrs {
+			vals = utils.List2Set(vals)
+			sort.Strings(vals)
+			attrs[name] = vals
+		}
+	}
+	return out
+}
+
+// helper function to find differences between two sets of DAS records
+func diffRecords(a, b []map[string]interface{}) DiffReport {
+	report := DiffReport{RecordsA: len(a), RecordsB: len(b), Changes: []RecordChange{}}
+	amap := indexRecords(a)
+	bmap := indexRecords(b)
+	for _, key := range sortedKeys(amap) {
+		battrs, ok := bmap[key]
+		if !ok {
+			report.Changes = append(report.Changes, RecordChange{Key: key, Change: "removed"})
+			report.Removed += 1
+			continue
+		}
+		aattrs := amap[key]
+		names := utils.List2Set(append(sortedKeys(aattrs), sortedKeys(battrs)...))
+		sort.Strings(names)
+		var attrs []AttributeChange
+		for _, name := range names {
+			aval := strings.Join(aattrs[name], ", ")
+			bval := strings.Join(battrs[name], ", ")
+			if aval != bval {
+				attrs = append(attrs, AttributeChange{Name: name, A: aval, B: bval})
+			}
+		}
+		if len(attrs) > 0 {
+			report.Changes = append(report.Changes, RecordChange{Key: key, Change: "changed", Attributes: attrs})
+			report.Changed += 1
+		} else {
+			report.Unchanged += 1
+		}
+	}
+	for _, key := range sortedKeys(bmap) {
+		if _, ok := amap[key]; !ok {
+			report.Changes = append(report.Changes, RecordChange{Key: key, Change: "added"})
+			report.Added += 1
+		}
+	}
+	return report
+}
+
+// helper function to read DAS records saved by dasgoclient, it supports
+// JSON list of records (-json option), das_client style output
+// (-format=json) and NDJSON (-format=ndjson), lines which are not JSON,
+// e.g. warnings of DAS query, are skipped
+func readRecords(r io.Reader) ([]map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]interface{}
+	var decoded, skipped bool
+	for {
+		data = bytes.TrimLeft(data, " \t\r\n")
+		if len(data) == 0 {
+			break
+		}
+		if data[0] != '[' && data[0] != '{' {
+			skipped = true
+			idx := bytes.IndexByte(data, '\n')
+			if idx < 0 {
+				break
+			}
+			data = data[idx+1:]
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		var val interface{}
+		if err := decoder.Decode(&val); err != nil {
+			return out, err
+		}
+		data = data[decoder.InputOffset():]
+		decoded = true
+		var items []interface{}
+		switch v := val.(type) {
+		case []interface{}:
+			items = v
+		case map[string]interface{}:
+			if data, ok := v["data"].([]interface{}); ok && v["status"] != nil {
+				items = data
+			} else {
+				items = []interface{}{v}
+			}
+		}
+		for _, item := range items {
+			if rec, ok := item.(map[string]interface{}); ok {
+				out = append(out, rec)
+			}
+		}
+	}
+	if skipped && !decoded {
+		return out, fmt.Errorf("no DAS records in JSON form, results should be saved with -json, -format=json or -format=ndjson option")
+	}
+	return out, nil
+}
+
+// helper function to read DAS records from given file, files with .gz and
+// .zst extensions are decompressed
+func readRecordsFile(fname string) ([]map[string]interface{}, error) {
+	data, err := readOutputFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return readRecords(bytes.NewReader(data))
+}
+
+// helper function to add DAS query condition, e.g. instance=int/global,
+// to DAS query before its filters and aggregators
+func addCondition(query, cond string) string {
+	if cond == "" {
+		return query
+	}
+	arr := strings.SplitN(query, "|", 2)
+	query = strings.TrimSpace(arr[0]) + " " + cond
+	if len(arr) == 2 {
+		query += " | " + strings.TrimSpace(arr[1])
+	}
+	return query
+}
+
+// helper function to run DAS query and return its DAS records
+func queryRecords(query string, opts Options, dmaps *dasmaps.DASMaps) ([]map[string]interface{}, int, string) {
+	// we always need complete DAS records to compare them
+	opts.JSON = true
+	res := runQuery(context.Background(), prepareQuery(query), opts, dmaps)
+	if res.ECode != 0 {
+		msg := res.Message
+		if msg == "" {
+			msg = res.Error
+		}
+		return nil, res.ECode, msg
+	}
+	var out []map[string]interface{}
+	for _, rec := range res.Records {
+		if err := recordError(rec); err != nil {
+			return nil, utils.DASServerError, fmt.Sprint(err)
+		}
+		out = append(out, map[string]interface{}(rec))
+	}
+	return out, 0, ""
+}
+
+// helper function to print differences between two sets of DAS records
+func (r DiffReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "--- a: %s (%d records)\n", r.A, r.RecordsA)
+	fmt.Fprintf(w, "+++ b: %s (%d records)\n", r.B, r.RecordsB)
+	marks := map[string]string{"added": "+", "removed": "-", "changed": "~"}
+	for _, c := range r.Changes {
+		fmt.Fprintf(w, "%s %s\n", marks[c.Change], c.Key)
+		for _, a := range c.Attributes {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", a.Name, a.A, a.B)
+		}
+	}
+	fmt.Fprintf(w, "%d added, %d removed, %d changed, %d unchanged\n", r.Added, r.Removed, r.Changed, r.Unchanged)
+}
+
+// helper function to compare results of DAS query with different
+// conditions or two files with saved results, it returns DAS exit code
+func diff(args []string, query, condA, condB string, opts Options) int {
+	var a, b []map[string]interface{}
+	var report DiffReport
+	if len(args) == 2 {
+		var err error
+		if a, err = readRecordsFile(args[0]); err != nil {
+			fmt.Println("ERROR: unable to read DAS records", err)
+			return utils.DASQueryError
+		}
+		if b, err = readRecordsFile(args[1]); err != nil {
+			fmt.Println("ERROR: unable to read DAS records", err)
+			return utils.DASQueryError
+		}
+		report = diffRecords(a, b)
+		report.A, report.B = args[0], args[1]
+	} else if len(args) == 0 && query != "" && (condA != "" || condB != "") {
+		queryA, queryB := addCondition(query, condA), addCondition(query, condB)
+		dmaps := loadDASMaps(opts.Profile)
+		var ecode int
+		var msg string
+		if a, ecode, msg = queryRecords(queryA, opts, dmaps); ecode != 0 {
+			fmt.Printf("ERROR: %s: %s\n", queryA, msg)
+			return ecode
+		}
+		if b, ecode, msg = queryRecords(queryB, opts, dmaps); ecode != 0 {
+			fmt.Printf("ERROR: %s: %s\n", queryB, msg)
+			return ecode
+		}
+		report = diffRecords(a, b)
+		report.A, report.B = queryA, queryB
+	} else {
+		fmt.Println("Usage: dasgoclient diff -query=<query> -a=<condition> -b=<condition>")
+		fmt.Println("       dasgoclient diff <file a> <file b>")
+		return utils.DASQueryError
+	}
+	if opts.JSON {
+		printJSON(report)
+	} else {
+		report.Print(os.Stdout)
+	}
+	return 0
+}
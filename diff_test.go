@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffRecords tests comparison of DAS records
+func TestDiffRecords(t *testing.T) {
+	assert := assert.New(t)
+	// DAS records of dasgoclient -json output
+	a, err := readRecords(strings.NewReader(`[
+{"das":{"expire":1,"instance":"prod/global","primary_key":"dataset.name","services":["dbs3:datasets"]},"dataset":[{"name":"/a/b/c","nevents":10}],"qhash":"123"} ,
+{"das":{"expire":1,"instance":"prod/global","primary_key":"dataset.name","services":["dbs3:datasets"]},"dataset":[{"name":"/a/b/d","nevents":20}],"qhash":"123"}
+]`))
+	assert.Nil(err)
+	assert.Equal(2, len(a))
+
+	// DAS records of dasgoclient -format=ndjson output
+	b, err := readRecords(strings.NewReader(`{"das":{"expire":2,"instance":"int/global","primary_key":"dataset.name","services":["dbs3:datasets"]},"dataset":[{"name":"/a/b/c","nevents":10}],"qhash":"456"}
+{"das":{"expire":2,"instance":"int/global","primary_key":"dataset.name","services":["dbs3:datasets"]},"dataset":[{"name":"/a/b/e","nevents":20}],"qhash":"456"}
+`))
+	assert.Nil(err)
+	assert.Equal(2, len(b))
+
+	report := diffRecords(a, b)
+	assert.Equal(1, report.Added)
+	assert.Equal(1, report.Removed)
+	assert.Equal(0, report.Changed)
+	assert.Equal(1, report.Unchanged)
+	assert.Equal([]RecordChange{{Key: "/a/b/d", Change: "removed"}, {Key: "/a/b/e", Change: "added"}}, report.Changes)
+
+	// DAS records of das_client style output
+	c, err := readRecords(strings.NewReader(`{"status":"ok", "ecode":"", "nresults":1, "data":[
+{"das":{"expire":3,"primary_key":"dataset.name","services":["dbs3:datasets"]},"dataset":[{"name":"/a/b/c","nevents":11}]}
+]}`))
+	assert.Nil(err)
+	assert.Equal(1, len(c))
+	report = diffRecords(a[:1], c)
+	assert.Equal(1, report.Changed)
+	assert.Equal([]AttributeChange{{Name: "dataset.nevents", A: "10", B: "11"}}, report.Changes[0].Attributes)
+
+	// DAS records returned by DAS query
+	rec := mongo.DASRecord{
+		"das":     mongo.DASRecord{"expire": 5, "primary_key": "dataset.name", "services": []string{"dbs3:datasets"}},
+		"dataset": []mongo.DASRecord{{"name": "/a/b/c", "nevents": 10}},
+		"qhash":   "789",
+	}
+	report = diffRecords(a[:1], []map[string]interface{}{rec})
+	assert.Equal(1, report.Unchanged)
+	assert.Equal(0, len(report.Changes))
+
+	_, err = readRecords(strings.NewReader(`[{"das":`))
+	assert.NotNil(err)
+}
+
+// TestReadRecordsFile tests reading of DAS records from (compressed) output
+// files and stdout of dasgoclient
+func TestReadRecordsFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	records := `[
+{"das":{"primary_key":"dataset.name"},"dataset":[{"name":"/a/b/c"}]},
+{"das":{"primary_key":"dataset.name"},"dataset":[{"name":"/a/b/d"}]}
+]
+`
+	ndjson := `{"das":{"primary_key":"dataset.name"},"dataset":[{"name":"/a/b/c"}]}
+{"das":{"primary_key":"dataset.name"},"dataset":[{"name":"/a/b/d"}]}
+`
+	for _, fname := range []string{"a.json", "a.json.gz", "a.json.zst", "a.ndjson.gz"} {
+		name := filepath.Join(dir, fname)
+		out, err := createOutput(name)
+		assert.Nil(err)
+		content := records
+		if strings.Contains(fname, "ndjson") {
+			content = ndjson
+		}
+		_, err = io.WriteString(out, content)
+		assert.Nil(err)
+		assert.Nil(out.Commit())
+		recs, err := readRecordsFile(name)
+		assert.Nil(err, fname)
+		assert.Equal(2, len(recs), fname)
+		assert.Equal("/a/b/d", primaryValue(recs[1]), fname)
+	}
+
+	// warnings of DAS query written to stdout along with DAS records
+	recs, err := readRecords(strings.NewReader("WARNING: DAS maps are stale\n" + records + "WARNING: No site records found in Rucio\n"))
+	assert.Nil(err)
+	assert.Equal(2, len(recs))
+	recs, err = readRecords(strings.NewReader("WARNING: DAS maps are stale\n[]\n"))
+	assert.Nil(err)
+	assert.Equal(0, len(recs))
+
+	// plain output does not provide DAS records
+	_, err = readRecords(strings.NewReader("/a/b/c\n/a/b/d\n"))
+	assert.NotNil(err)
+	_, err = readRecordsFile(filepath.Join(dir, "missing.json.gz"))
+	assert.NotNil(err)
+}
+
+// TestAddCondition tests adding conditions to DAS query
+func TestAddCondition(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("file dataset=/a/b/c", addCondition("file dataset=/a/b/c", ""))
+	assert.Equal("file dataset=/a/b/c instance=int/global", addCondition("file dataset=/a/b/c", "instance=int/global"))
+	assert.Equal("file dataset=/a/b/c system=rucio | grep file.size", addCondition("file dataset=/a/b/c | grep file.size", "system=rucio"))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/services"
+)
+
+// default CMS web frontend used in DAS maps
+var dasMapsHost = "https://cmsweb.cern.ch"
+
+// Profile represents dasgoclient environment, i.e. CMS web frontend, base
+// urls of individual CMS data-services and default DBS instance
+type Profile struct {
+	Name     string            `json:"name"`
+	Host     string            `json:"host"`
+	Instance string            `json:"instance"`
+	Services map[string]string `json:"services,omitempty"`
+}
+
+// global table of dasgoclient environments
+var dasProfiles = map[string]Profile{
+	"prod":    {Name: "prod", Host: "https://cmsweb.cern.ch", Instance: "prod/global"},
+	"preprod": {Name: "preprod", Host: "https://cmsweb-preprod.cern.ch", Instance: "int/global"},
+	"testbed": {Name: "testbed", Host: "https://cmsweb-testbed.cern.ch", Instance: "int/global"},
+	"local":   {Name: "local", Host: "https://localhost:8443", Instance: "int/global"},
+}
+
+// helper function to get host name of given url
+func hostname(rurl string) string {
+	if u, err := url.Parse(rurl); err == nil {
+		return u.Hostname()
+	}
+	return ""
+}
+
+// helper function to parse base urls of CMS data-services, e.g.
+// dbs3=http://localhost:8989,rucio=https://rucio.example.com
+func parseServiceUrls(spec string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		arr := strings.SplitN(item, "=", 2)
+		if len(arr) != 2 || arr[0] == "" {
+			return nil, fmt.Errorf("invalid service url entry '%s', should be system=url", item)
+		}
+		if u, err := url.Parse(arr[1]); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid url '%s' for %s", arr[1], arr[0])
+		}
+		out[arr[0]] = strings.TrimSuffix(arr[1], "/")
+	}
+	return out, nil
+}
+
+// NewProfile returns dasgoclient environment for given name, host and
+// service urls, empty name means that environment is chosen by host and
+// unknown hosts use integration DBS instance
+func NewProfile(env, host, serviceUrls string) (Profile, error) {
+	host = strings.TrimSuffix(host, "/")
+	var profile Profile
+	if env != "" {
+		p, ok := dasProfiles[env]
+		if !ok {
+			return profile, fmt.Errorf("unknown environment '%s', should be one of %s", env, strings.Join(sortedKeys(dasProfiles), ", "))
+		}
+		profile = p
+		if host != "" {
+			profile.Host = host
+		}
+	} else {
+		profile = Profile{Name: "custom", Host: host, Instance: "int/global"}
+		for _, name := range sortedKeys(dasProfiles) {
+			if hostname(dasProfiles[name].Host) == hostname(host) {
+				profile = dasProfiles[name]
+				profile.Host = host
+			}
+		}
+	}
+	srvs := make(map[string]string)
+	for k, v := range profile.Services {
+		srvs[k] = v
+	}
+	overrides, err := parseServiceUrls(serviceUrls)
+	if err != nil {
+		return profile, err
+	}
+	for k, v := range overrides {
+		srvs[k] = v
+	}
+	profile.Services = nil
+	if len(srvs) > 0 {
+		profile.Services = srvs
+	}
+	return profile, nil
+}
+
+// String returns string representation of dasgoclient environment
+func (p Profile) String() string {
+	s := fmt.Sprintf("%s host=%s instance=%s", p.Name, p.Host, p.Instance)
+	for _, srv := range sortedKeys(p.Services) {
+		s += fmt.Sprintf(" %s=%s", srv, p.Services[srv])
+	}
+	return s
+}
+
+// Setup configures das2go services module to use urls of the environment
+func (p Profile) Setup() {
+	services.FrontendURL = p.Host
+	services.UrlMap = p.Services
+}
+
+// helper function to replace scheme and host of given url
+func replaceBase(rurl, base string) string {
+	u, err := url.Parse(rurl)
+	if err != nil || u.Host == "" {
+		return rurl
+	}
+	return base + strings.TrimPrefix(rurl, u.Scheme+"://"+u.Host)
+}
+
+// AdjustMaps changes urls of DAS maps according to the environment, urls of
+// CMS web frontend are moved to environment host unless CMS data-service
+// has its own base url
+func (p Profile) AdjustMaps(dmaps *dasmaps.DASMaps) {
+	for _, rec := range dmaps.Maps() {
+		rurl, ok := rec["url"].(string)
+		if !ok || !strings.HasPrefix(rurl, "http") {
+			continue
+		}
+		system, _ := rec["system"].(string)
+		if base, ok := p.Services[system]; ok {
+			rec["url"] = replaceBase(rurl, base)
+		} else if p.Host != dasMapsHost && strings.HasPrefix(rurl, dasMapsHost) {
+			rec["url"] = p.Host + strings.TrimPrefix(rurl, dasMapsHost)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewProfile tests selection of dasgoclient environment
+func TestNewProfile(t *testing.T) {
+	assert := assert.New(t)
+	p, err := NewProfile("", "https://cmsweb.cern.ch", "")
+	assert.Nil(err)
+	assert.Equal("prod", p.Name)
+	assert.Equal("prod/global", p.Instance)
+
+	p, err = NewProfile("", "https://cmsweb-testbed.cern.ch:8443/", "")
+	assert.Nil(err)
+	assert.Equal("testbed", p.Name)
+	assert.Equal("https://cmsweb-testbed.cern.ch:8443", p.Host)
+	assert.Equal("int/global", p.Instance)
+
+	p, err = NewProfile("", "http://localhost:9999", "")
+	assert.Nil(err)
+	assert.Equal("local", p.Name)
+	assert.Equal("http://localhost:9999", p.Host)
+
+	p, err = NewProfile("", "http://127.0.0.1:9999", "")
+	assert.Nil(err)
+	assert.Equal("custom", p.Name)
+	assert.Equal("int/global", p.Instance)
+
+	p, err = NewProfile("preprod", "", "dbs3=http://localhost:8989/")
+	assert.Nil(err)
+	assert.Equal("https://cmsweb-preprod.cern.ch", p.Host)
+	assert.Equal(map[string]string{"dbs3": "http://localhost:8989"}, p.Services)
+	assert.Equal("preprod host=https://cmsweb-preprod.cern.ch instance=int/global dbs3=http://localhost:8989", p.String())
+
+	// environment table should not be modified by service urls
+	assert.Nil(dasProfiles["preprod"].Services)
+
+	_, err = NewProfile("dev", "", "")
+	assert.NotNil(err)
+	_, err = NewProfile("prod", "", "dbs3")
+	assert.NotNil(err)
+	_, err = NewProfile("prod", "", "dbs3=localhost")
+	assert.NotNil(err)
+}
+
+// TestAdjustMaps tests adjustment of DAS maps urls for given environment
+func TestAdjustMaps(t *testing.T) {
+	assert := assert.New(t)
+	urls := func(p Profile) map[string]string {
+		var dmaps dasmaps.DASMaps
+		dmaps.ReadMapFile("testdata/das_maps.js")
+		p.AdjustMaps(&dmaps)
+		return map[string]string{
+			"dbs3":  dmaps.FindApiRecord("dbs3", "datasets")["url"].(string),
+			"rucio": dmaps.FindApiRecord("rucio", "file4dataset")["url"].(string),
+			"local": dmaps.FindApiRecord("dbs3", "datasetlist")["url"].(string),
+		}
+	}
+	prod := urls(dasProfiles["prod"])
+	assert.Equal("https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets/", prod["dbs3"])
+	assert.Equal("local_api", prod["local"])
+
+	testbed := urls(dasProfiles["testbed"])
+	assert.Equal("https://cmsweb-testbed.cern.ch/dbs/prod/global/DBSReader/datasets/", testbed["dbs3"])
+	assert.Equal(prod["rucio"], testbed["rucio"])
+
+	p, err := NewProfile("testbed", "", "dbs3=http://localhost:8989,rucio=https://rucio.example.com")
+	assert.Nil(err)
+	custom := urls(p)
+	assert.Equal("http://localhost:8989/dbs/prod/global/DBSReader/datasets/", custom["dbs3"])
+	assert.Equal("https://rucio.example.com/replicas/cms", custom["rucio"])
+	assert.Equal("local_api", custom["local"])
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dmwm/das2go/utils"
+)
+
+// DAS query examples embedded into dasgoclient executable
+//
+//go:embed examples/*.txt
+var examplesFS embed.FS
+
+// list of DAS query example files, the file prefix defines its category
+var exampleFiles = []string{"block_queries.txt", "file_queries.txt", "lumi_queries.txt", "mcm_queries.txt", "run_queries.txt", "dataset_queries.txt", "jobsummary_queries.txt", "misc_queries.txt", "site_queries.txt"}
+
+// examplesFlag represents -examples option which can be used either as
+// boolean flag or with a keyword to search DAS query examples
+type examplesFlag struct {
+	Enabled bool
+	Keyword string
+}
+
+// String implements flag.Value interface
+func (f *examplesFlag) String() string {
+	if f == nil || !f.Enabled {
+		return "false"
+	}
+	if f.Keyword != "" {
+		return f.Keyword
+	}
+	return "true"
+}
+
+// Set implements flag.Value interface
+func (f *examplesFlag) Set(val string) error {
+	switch val {
+	case "true":
+		f.Enabled, f.Keyword = true, ""
+	case "false":
+		f.Enabled, f.Keyword = false, ""
+	default:
+		f.Enabled, f.Keyword = true, val
+	}
+	return nil
+}
+
+// IsBoolFlag allows to use -examples option without a value
+func (f *examplesFlag) IsBoolFlag() bool {
+	return true
+}
+
+// helper function to load DAS query examples of given file, files in given
+// directory take precedence over embedded ones
+func loadExamples(fname, dir string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, fname))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	data, err := examplesFS.ReadFile("examples/" + fname)
+	return string(data), err
+}
+
+// helper function to check if DAS query example matches given keyword
+func (e DASExample) match(keyword string) bool {
+	keyword = strings.ToLower(keyword)
+	return strings.Contains(strings.ToLower(e.Query), keyword) ||
+		strings.Contains(strings.ToLower(e.Description), keyword)
+}
+
+// helper function to get DAS query examples grouped by category, if keyword
+// is provided we only return examples of matched category or examples which
+// contain the keyword
+func dasExamples(dir, keyword string) ([]DASExamples, error) {
+	var out []DASExamples
+	for _, fname := range exampleFiles {
+		category := strings.Split(fname, "_")[0]
+		text, err := loadExamples(fname, dir)
+		if err != nil {
+			return out, err
+		}
+		examples := parseExamples(category, text)
+		if keyword != "" && !strings.EqualFold(keyword, category) {
+			var matched []DASExample
+			for _, e := range examples.Examples {
+				if e.match(keyword) {
+					matched = append(matched, e)
+				}
+			}
+			if len(matched) == 0 {
+				continue
+			}
+			examples.Examples = matched
+		}
+		out = append(out, examples)
+	}
+	return out, nil
+}
+
+// helper function to show examples of DAS queries
+func showExamples(jsonout bool, dir, keyword string) {
+	examples, err := dasExamples(dir, keyword)
+	if err != nil {
+		fmt.Println("ERROR: unable to load DAS query examples", err)
+		os.Exit(utils.DASQueryError)
+	}
+	if jsonout {
+		printJSON(examples)
+		return
+	}
+	for _, cat := range examples {
+		msg := fmt.Sprintf("### %s queries:\n", cat.Category)
+		fmt.Println(strings.ToTitle(msg))
+		for idx, e := range cat.Examples {
+			if idx == 0 || e.Description != cat.Examples[idx-1].Description {
+				if idx > 0 {
+					fmt.Println()
+				}
+				if e.Description != "" {
+					fmt.Printf("# %s\n", e.Description)
+				}
+			}
+			fmt.Println(e.Query)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// DAS query examples which are known to fail validation of DAS query spec
+// patterns along with the reason, they are kept as documented by upstream
+// but DAS server rejects them
+var knownFailingExamples = map[string]string{
+	"site=T3_*":                         "site values must match T<tier>_<country>_<name> pattern, e.g. T3_US_Cornell",
+	"block site=osg-se.cac.cornell.edu": "site values must be CMS site names, storage element names are rejected",
+	"block block=/Cosmics/Run2010B-TkAlCosmics0T* site=T1_DE_KIT*": "block values must match /primary/processed/tier#uuid pattern",
+}
+
+// TestExamples validates embedded DAS query examples against DAS maps, real
+// DAS maps can be provided via DAS_MAPS_FILE environment (see examples target
+// of Makefile) otherwise DAS maps bundled in testdata area are used
+func TestExamples(t *testing.T) {
+	assert := assert.New(t)
+	fname := os.Getenv("DAS_MAPS_FILE")
+	if fname == "" {
+		fname = "testdata/das_maps.js"
+	}
+	t.Log("validate DAS query examples against", fname)
+	var dmaps dasmaps.DASMaps
+	dmaps.ReadMapFile(fname)
+	daskeys := dmaps.DASKeys()
+	assert.NotEqual(0, len(daskeys))
+
+	// dasql parser logs its errors, we report them ourselves
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	examples, err := dasExamples("", "")
+	assert.Nil(err)
+	assert.Equal(len(exampleFiles), len(examples))
+	for _, cat := range examples {
+		assert.NotEqual(0, len(cat.Examples), cat.Category)
+		for _, e := range cat.Examples {
+			res := lintQuery(LintQuery{Source: cat.Category, Query: e.Query}, daskeys)
+			if reason, ok := knownFailingExamples[e.Query]; ok {
+				t.Logf("known failing example %q: %s", e.Query, reason)
+				assert.Equal(utils.DASValidationError, res.ECode, e.Query+": known failure is fixed, remove it from knownFailingExamples")
+				continue
+			}
+			assert.Equal(0, res.ECode, e.Query+": "+res.Error)
+		}
+	}
+}
+
+// TestExamplesSearch tests search of DAS query examples
+func TestExamplesSearch(t *testing.T) {
+	assert := assert.New(t)
+	examples, err := dasExamples("", "lumi")
+	assert.Nil(err)
+	for _, cat := range examples {
+		if cat.Category == "lumi" {
+			continue
+		}
+		for _, e := range cat.Examples {
+			assert.True(strings.Contains(strings.ToLower(e.Query+e.Description), "lumi"), e.Query)
+		}
+	}
+
+	var flag examplesFlag
+	assert.Nil(flag.Set("true"))
+	assert.True(flag.Enabled)
+	assert.Equal("", flag.Keyword)
+	assert.Nil(flag.Set("lumi"))
+	assert.Equal("lumi", flag.Keyword)
+}
@@ -0,0 +1,879 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+)
+
+// comparison operators of DAS filter expressions
+var filterOperators = []string{"=", "==", "!=", ">", "<", ">=", "<=", "~", "!~", "like"}
+
+// FilterExpr represents DAS filter expression, e.g.
+// file.size>1e9 and (file.name like *RAW* or not file.nevents)
+type FilterExpr struct {
+	Op    string        // and, or, not, exists or comparison operator
+	Attr  string        // dotted attribute name, e.g. file.size
+	Value string        // value to compare attribute with
+	Args  []*FilterExpr // arguments of and, or and not operators
+	re    *regexp.Regexp
+}
+
+// SortKey represents attribute used to sort DAS records
+type SortKey struct {
+	Attr string
+	Desc bool
+}
+
+// PipeStage represents single stage of DAS query pipe, i.e. grep, sort or
+// unique filter
+type PipeStage struct {
+	Name  string        // name of the filter
+	Exprs []*FilterExpr // expressions of grep filter
+	Keys  []SortKey     // keys of sort filter
+}
+
+// PipeError represents parse error of DAS query pipe along with offset of
+// the token which caused it
+type PipeError struct {
+	Pos int    // offset of the token within the pipe
+	Msg string // error message
+}
+
+// Error implements error interface
+func (e *PipeError) Error() string {
+	return e.Msg
+}
+
+// Pipeline represents DAS query pipe, e.g.
+// grep file.name, file.size>1e9 | sort -file.size | count
+type Pipeline struct {
+	Stages      []PipeStage
+	Aggregators [][]string
+	GroupBy     []string
+}
+
+// helper function to split DAS query pipe into tokens and their offsets,
+// quoted values are kept as is
+func pipeTokens(pipe string) ([]string, []int, error) {
+	var tokens []string
+	var offsets []int
+	ops := []string{">=", "<=", "!=", "==", "!~", "&&", "||", "(", ")", ",", "|", "=", ">", "<", "~", "!"}
+	for idx := 0; idx < len(pipe); {
+		c := pipe[idx]
+		if c == ' ' || c == '\t' || c == '\n' {
+			idx += 1
+			continue
+		}
+		if c == '"' || c == '\'' {
+			end := strings.IndexByte(pipe[idx+1:], c)
+			if end < 0 {
+				return tokens, offsets, &PipeError{Pos: idx, Msg: fmt.Sprintf("unterminated quoted value at position %d", idx)}
+			}
+			tokens = append(tokens, pipe[idx:idx+end+2])
+			offsets = append(offsets, idx)
+			idx += end + 2
+			continue
+		}
+		op := ""
+		for _, o := range ops {
+			if strings.HasPrefix(pipe[idx:], o) {
+				op = o
+				break
+			}
+		}
+		if op != "" {
+			tokens = append(tokens, op)
+			offsets = append(offsets, idx)
+			idx += len(op)
+			continue
+		}
+		end := idx
+		for end < len(pipe) && !strings.ContainsRune(" \t\n\"'()=,|<>!~&", rune(pipe[end])) {
+			end += 1
+		}
+		if end == idx {
+			// single ampersand is not an operator, keep it within value
+			end += 1
+		}
+		tokens = append(tokens, pipe[idx:end])
+		offsets = append(offsets, idx)
+		idx = end
+	}
+	return tokens, offsets, nil
+}
+
+// helper function to remove quotes of quoted value
+func unquote(val string) string {
+	if len(val) > 1 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+		return val[1 : len(val)-1]
+	}
+	return val
+}
+
+// helper function to find index of first | character of DAS query outside
+// of quoted values, it returns -1 if DAS query has no pipe
+func pipeIndex(query string) int {
+	var quote byte
+	for idx := 0; idx < len(query); idx++ {
+		c := query[idx]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '|':
+			return idx
+		}
+	}
+	return -1
+}
+
+// helper function to split DAS query into its conditions and pipe, the
+// pipe starts at first | character outside of quoted values
+func splitPipe(query string) (string, string) {
+	if idx := pipeIndex(query); idx >= 0 {
+		return strings.TrimSpace(query[:idx]), strings.TrimSpace(query[idx+1:])
+	}
+	return strings.TrimSpace(query), ""
+}
+
+// pipeParser represents parser of DAS query pipe tokens
+type pipeParser struct {
+	tokens  []string
+	offsets []int // offsets of tokens within the pipe
+	size    int   // length of the pipe
+	pos     int
+}
+
+// helper function to create parse error at given token index
+func (p *pipeParser) errorf(tok int, format string, args ...interface{}) error {
+	pos := p.size
+	if tok >= 0 && tok < len(p.offsets) {
+		pos = p.offsets[tok]
+	}
+	return &PipeError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// helper function to add prefix to parse error keeping its position
+func prefixError(prefix string, err error) error {
+	if e, ok := err.(*PipeError); ok {
+		return &PipeError{Pos: e.Pos, Msg: prefix + e.Msg}
+	}
+	return fmt.Errorf("%s%v", prefix, err)
+}
+
+// helper function to peek current token
+func (p *pipeParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// helper function to consume current token
+func (p *pipeParser) next() string {
+	tok := p.peek()
+	p.pos += 1
+	return tok
+}
+
+// helper function to check if current token ends stage of the pipe
+func (p *pipeParser) endOfStage() bool {
+	return p.pos >= len(p.tokens) || p.peek() == "|"
+}
+
+// helper function to parse or expression
+func (p *pipeParser) parseOr() (*FilterExpr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" || p.peek() == "||" {
+		p.next()
+		arg, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		expr = &FilterExpr{Op: "or", Args: []*FilterExpr{expr, arg}}
+	}
+	return expr, nil
+}
+
+// helper function to parse and expression
+func (p *pipeParser) parseAnd() (*FilterExpr, error) {
+	expr, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" || p.peek() == "&&" {
+		p.next()
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		expr = &FilterExpr{Op: "and", Args: []*FilterExpr{expr, arg}}
+	}
+	return expr, nil
+}
+
+// helper function to parse negation, parenthesis or comparison
+func (p *pipeParser) parseUnary() (*FilterExpr, error) {
+	switch tok := p.peek(); tok {
+	case "not", "!":
+		p.next()
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &FilterExpr{Op: "not", Args: []*FilterExpr{arg}}, nil
+	case "(":
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, p.errorf(p.pos-1, "missing closing parenthesis")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+// helper function to parse comparison of attribute with a value
+func (p *pipeParser) parseComparison() (*FilterExpr, error) {
+	attr := p.next()
+	if !isAttribute(attr) {
+		return nil, p.errorf(p.pos-1, "expected attribute name instead of %q", attr)
+	}
+	expr := &FilterExpr{Op: "exists", Attr: attr}
+	if !utils.InList(p.peek(), filterOperators) {
+		return expr, nil
+	}
+	expr.Op = p.next()
+	if expr.Op == "==" {
+		expr.Op = "="
+	}
+	if p.endOfStage() || utils.InList(p.peek(), []string{",", ")", "(", "and", "or", "&&", "||"}) {
+		return nil, p.errorf(p.pos, "missing value of %s %s", attr, expr.Op)
+	}
+	expr.Value = unquote(p.next())
+	var err error
+	switch expr.Op {
+	case "~", "!~":
+		expr.re, err = regexp.Compile(expr.Value)
+	case "like":
+		pat := regexp.QuoteMeta(expr.Value)
+		pat = strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(pat)
+		expr.re, err = regexp.Compile("^" + pat + "$")
+	}
+	if err != nil {
+		return nil, p.errorf(p.pos-1, "invalid pattern %q: %v", expr.Value, err)
+	}
+	return expr, nil
+}
+
+// helper function to check if given token is valid attribute name
+func isAttribute(tok string) bool {
+	if tok == "" || strings.ContainsAny(tok, "\"'()=,|<>!~&*") {
+		return false
+	}
+	return !utils.InList(tok, []string{"and", "or", "not", "like"})
+}
+
+// helper function to parse arguments of sort filter, keys are sorted in
+// ascending order unless they are prefixed with minus sign or have :desc
+// suffix, e.g. sort -file.size, file.name:asc
+func (p *pipeParser) parseSortKeys() ([]SortKey, error) {
+	var keys []SortKey
+	for {
+		tok := p.next()
+		var key SortKey
+		switch {
+		case strings.HasPrefix(tok, "-"):
+			key = SortKey{Attr: tok[1:], Desc: true}
+		case strings.HasSuffix(tok, ":desc"):
+			key = SortKey{Attr: strings.TrimSuffix(tok, ":desc"), Desc: true}
+		default:
+			key = SortKey{Attr: strings.TrimSuffix(tok, ":asc")}
+		}
+		if !isAttribute(key.Attr) {
+			return nil, p.errorf(p.pos-1, "expected sort key instead of %q", tok)
+		}
+		keys = append(keys, key)
+		if p.peek() != "," {
+			return keys, nil
+		}
+		p.next()
+	}
+}
+
+// helper function to parse aggregator, e.g. sum(file.size) or count
+func (p *pipeParser) parseAggregator() ([]string, error) {
+	name := p.next()
+	if name == "count" && p.peek() != "(" {
+		// count of records, its key is assigned once we know select key
+		return []string{name, ""}, nil
+	}
+	if p.next() != "(" {
+		return nil, p.errorf(p.pos-1, "wrong representation of %s aggregator, expected %s(attribute)", name, name)
+	}
+	attr := p.next()
+	if !isAttribute(attr) || p.next() != ")" {
+		return nil, p.errorf(p.pos-1, "wrong representation of %s aggregator, expected %s(attribute)", name, name)
+	}
+	return []string{name, attr}, nil
+}
+
+// helper function to parse optional group by clause of aggregators, e.g.
+// sum(file.size) group by file.dataset
+func (p *pipeParser) parseGroupBy(groupBy []string) ([]string, error) {
+	if p.peek() != "group" {
+		return groupBy, nil
+	}
+	p.next()
+	if p.next() != "by" {
+		return groupBy, p.errorf(p.pos-1, "expected group by attributes")
+	}
+	if len(groupBy) > 0 {
+		return groupBy, p.errorf(p.pos-2, "aggregators can be grouped only once")
+	}
+	for {
+		attr := p.next()
+		if !isAttribute(attr) {
+			return groupBy, p.errorf(p.pos-1, "expected group by attribute instead of %q", attr)
+		}
+		groupBy = append(groupBy, attr)
+		if p.peek() != "," {
+			return groupBy, nil
+		}
+		p.next()
+	}
+}
+
+// helper function to parse DAS query pipe
+func parsePipeline(pipe string) (Pipeline, error) {
+	var pipeline Pipeline
+	tokens, offsets, err := pipeTokens(pipe)
+	if err != nil {
+		return pipeline, err
+	}
+	p := &pipeParser{tokens: tokens, offsets: offsets, size: len(pipe)}
+	aggregators := append([]string{"mean"}, dasAggregators...)
+	for {
+		if p.endOfStage() {
+			return pipeline, p.errorf(p.pos, "empty filter of DAS query pipe")
+		}
+		name := p.peek()
+		if len(pipeline.Aggregators) > 0 && !utils.InList(name, aggregators) {
+			return pipeline, p.errorf(p.pos, "%s filter can't follow aggregators", name)
+		}
+		switch {
+		case name == "grep":
+			p.next()
+			stage := PipeStage{Name: name}
+			for {
+				expr, err := p.parseOr()
+				if err != nil {
+					return pipeline, prefixError("grep: ", err)
+				}
+				stage.Exprs = append(stage.Exprs, expr)
+				if p.peek() != "," {
+					break
+				}
+				p.next()
+			}
+			pipeline.Stages = append(pipeline.Stages, stage)
+		case name == "sort":
+			p.next()
+			keys, err := p.parseSortKeys()
+			if err != nil {
+				return pipeline, prefixError("sort: ", err)
+			}
+			pipeline.Stages = append(pipeline.Stages, PipeStage{Name: name, Keys: keys})
+		case name == "unique":
+			p.next()
+			pipeline.Stages = append(pipeline.Stages, PipeStage{Name: name})
+		case utils.InList(name, aggregators):
+			for {
+				agg, err := p.parseAggregator()
+				if err != nil {
+					return pipeline, err
+				}
+				pipeline.Aggregators = append(pipeline.Aggregators, agg)
+				if p.peek() != "," {
+					break
+				}
+				p.next()
+			}
+			if pipeline.GroupBy, err = p.parseGroupBy(pipeline.GroupBy); err != nil {
+				return pipeline, err
+			}
+		default:
+			return pipeline, p.errorf(p.pos, "unknown filter %q", name)
+		}
+		if p.endOfStage() {
+			if p.next() == "" {
+				return pipeline, nil
+			}
+			continue
+		}
+		return pipeline, p.errorf(p.pos, "unexpected %q in %s filter", p.peek(), name)
+	}
+}
+
+// helper function to collect attributes of filter expression
+func (e *FilterExpr) attributes(out []string) []string {
+	if e.Attr != "" && !utils.InList(e.Attr, out) {
+		out = append(out, e.Attr)
+	}
+	for _, arg := range e.Args {
+		out = arg.attributes(out)
+	}
+	return out
+}
+
+// Attributes returns attributes of DAS records selected by last grep filter
+func (p Pipeline) Attributes() []string {
+	var out []string
+	for _, stage := range p.Stages {
+		if stage.Name != "grep" {
+			continue
+		}
+		out = nil
+		for _, expr := range stage.Exprs {
+			out = expr.attributes(out)
+		}
+	}
+	return out
+}
+
+// Sorted returns true if DAS records are sorted by the pipe
+func (p Pipeline) Sorted() bool {
+	for _, stage := range p.Stages {
+		if stage.Name == "sort" {
+			return true
+		}
+	}
+	return false
+}
+
+// Filters returns filters of the pipe in a form of dasql parser
+func (p Pipeline) Filters() map[string][]string {
+	filters := make(map[string][]string)
+	for _, stage := range p.Stages {
+		switch stage.Name {
+		case "grep":
+			filters["grep"] = p.Attributes()
+		case "sort":
+			for _, key := range stage.Keys {
+				attr := key.Attr
+				if key.Desc {
+					attr = "-" + attr
+				}
+				filters["sort"] = append(filters["sort"], attr)
+			}
+		case "unique":
+			filters["unique"] = []string{"1"}
+		}
+	}
+	return filters
+}
+
+// helper function to convert DAS record into generic JSON form, numbers
+// are kept in their original representation
+func genericRecord(rec mongo.DASRecord) map[string]interface{} {
+	var out map[string]interface{}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return out
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.Decode(&out)
+	return out
+}
+
+// helper function to collect values of dotted attribute of generic DAS
+// record, all elements of lists are walked through
+func attributeValuesOf(val interface{}, keys []string, out []interface{}) []interface{} {
+	switch v := val.(type) {
+	case []interface{}:
+		for _, item := range v {
+			out = attributeValuesOf(item, keys, out)
+		}
+		return out
+	case map[string]interface{}:
+		if len(keys) == 0 {
+			return append(out, v)
+		}
+		if item, ok := v[keys[0]]; ok {
+			return attributeValuesOf(item, keys[1:], out)
+		}
+		return out
+	case nil:
+		return out
+	}
+	if len(keys) == 0 {
+		return append(out, val)
+	}
+	return out
+}
+
+// helper function to get values of dotted attribute of generic DAS record
+func recordValues(rec map[string]interface{}, attr string) []interface{} {
+	return attributeValuesOf(rec, strings.Split(attr, "."), nil)
+}
+
+// helper function to convert value of DAS record into string
+func formatValue(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(data)
+}
+
+// helper function to convert value into number if possible
+func numericValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// helper function to compare two values, numbers are compared by their
+// values and other values by their string representation. Numbers precede
+// other values to keep the order transitive for mixed values, e.g. 9 < 10 <
+// 10a, and NaN is not a number.
+func compareValues(a, b interface{}) int {
+	x, xnum := numericValue(a)
+	y, ynum := numericValue(b)
+	xnum = xnum && !math.IsNaN(x)
+	ynum = ynum && !math.IsNaN(y)
+	switch {
+	case xnum && ynum:
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case xnum:
+		return -1
+	case ynum:
+		return 1
+	}
+	return strings.Compare(formatValue(a), formatValue(b))
+}
+
+// Match checks if generic DAS record matches filter expression, the
+// comparison is satisfied if any value of the attribute satisfies it
+func (e *FilterExpr) Match(rec map[string]interface{}) bool {
+	switch e.Op {
+	case "and":
+		return e.Args[0].Match(rec) && e.Args[1].Match(rec)
+	case "or":
+		return e.Args[0].Match(rec) || e.Args[1].Match(rec)
+	case "not":
+		return !e.Args[0].Match(rec)
+	case "exists":
+		return len(recordValues(rec, e.Attr)) > 0
+	case "!=":
+		return !(&FilterExpr{Op: "=", Attr: e.Attr, Value: e.Value}).Match(rec)
+	case "!~":
+		return !(&FilterExpr{Op: "~", Attr: e.Attr, Value: e.Value, re: e.re}).Match(rec)
+	}
+	for _, val := range recordValues(rec, e.Attr) {
+		var match bool
+		switch e.Op {
+		case "~", "like":
+			match = e.re.MatchString(formatValue(val))
+		case "=":
+			match = compareValues(val, e.Value) == 0
+		case ">":
+			match = compareValues(val, e.Value) > 0
+		case "<":
+			match = compareValues(val, e.Value) < 0
+		case ">=":
+			match = compareValues(val, e.Value) >= 0
+		case "<=":
+			match = compareValues(val, e.Value) <= 0
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply applies grep, sort and unique filters of the pipe to DAS records,
+// records with errors are kept intact
+func (p Pipeline) Apply(records []mongo.DASRecord) []mongo.DASRecord {
+	if len(p.Stages) == 0 {
+		return records
+	}
+	// defer function profiler
+	defer utils.MeasureTime("dasgoclient/filters")
+
+	generic := make([]map[string]interface{}, len(records))
+	for idx, rec := range records {
+		generic[idx] = genericRecord(rec)
+	}
+	var attrs []string
+	for _, stage := range p.Stages {
+		var recs []mongo.DASRecord
+		var grecs []map[string]interface{}
+		switch stage.Name {
+		case "grep":
+			attrs = nil
+			for _, expr := range stage.Exprs {
+				attrs = expr.attributes(attrs)
+			}
+			for idx, rec := range records {
+				match := true
+				for _, expr := range stage.Exprs {
+					// plain attributes select values to show, they
+					// don't filter DAS records
+					if expr.Op != "exists" && recordError(rec) == nil && !expr.Match(generic[idx]) {
+						match = false
+						break
+					}
+				}
+				if match {
+					recs = append(recs, rec)
+					grecs = append(grecs, generic[idx])
+				}
+			}
+		case "sort":
+			idx := make([]int, len(records))
+			for i := range idx {
+				idx[i] = i
+			}
+			sort.SliceStable(idx, func(i, j int) bool {
+				return compareRecords(generic[idx[i]], generic[idx[j]], stage.Keys) < 0
+			})
+			for _, i := range idx {
+				recs = append(recs, records[i])
+				grecs = append(grecs, generic[i])
+			}
+		case "unique":
+			seen := make(map[string]bool)
+			for idx, rec := range records {
+				key := uniqueKey(generic[idx], attrs)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				recs = append(recs, rec)
+				grecs = append(grecs, generic[idx])
+			}
+		}
+		records, generic = recs, grecs
+	}
+	return records
+}
+
+// helper function to compare generic DAS records by given sort keys,
+// records without sort key are placed at the end
+func compareRecords(a, b map[string]interface{}, keys []SortKey) int {
+	for _, key := range keys {
+		avals, bvals := recordValues(a, key.Attr), recordValues(b, key.Attr)
+		switch {
+		case len(avals) == 0 && len(bvals) == 0:
+			continue
+		case len(avals) == 0:
+			return 1
+		case len(bvals) == 0:
+			return -1
+		}
+		if cmp := compareValues(avals[0], bvals[0]); cmp != 0 {
+			if key.Desc {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return 0
+}
+
+// helper function to get key of generic DAS record used by unique filter,
+// it is either values of selected attributes or primary key value
+func uniqueKey(rec map[string]interface{}, attrs []string) string {
+	if len(attrs) == 0 {
+		return primaryValue(rec)
+	}
+	var out []string
+	for _, attr := range attrs {
+		out = append(out, strings.Join(filteredValues(rec, attr), ","))
+	}
+	return strings.Join(out, "\x00")
+}
+
+// helper function to get unique string values of attribute of generic
+// DAS record
+func filteredValues(rec map[string]interface{}, attr string) []string {
+	var out []string
+	for _, val := range recordValues(rec, attr) {
+		out = append(out, formatValue(val))
+	}
+	return utils.List2Set(out)
+}
+
+// helper function to get unique values of attribute of generic DAS record,
+// values are kept in their original types
+func uniqueValues(rec map[string]interface{}, attr string) []interface{} {
+	var out []interface{}
+	seen := make(map[string]bool)
+	for _, val := range recordValues(rec, attr) {
+		if key := formatValue(val); !seen[key] {
+			seen[key] = true
+			out = append(out, val)
+		}
+	}
+	return out
+}
+
+// helper function to get values of attribute of generic DAS record used by
+// aggregators, identical sub-records, e.g. the same file provided by
+// several CMS data-services, are counted once while equal values of
+// different sub-records are kept
+func aggregatedValues(rec map[string]interface{}, attr string) []interface{} {
+	return distinctValuesOf(rec, strings.Split(attr, "."), nil)
+}
+
+// helper function to collect values of dotted attribute of generic DAS
+// record skipping duplicate sub-records of its lists
+func distinctValuesOf(val interface{}, keys []string, out []interface{}) []interface{} {
+	switch v := val.(type) {
+	case []interface{}:
+		seen := make(map[string]bool)
+		for _, item := range v {
+			if _, ok := item.(map[string]interface{}); ok {
+				key := formatValue(item)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			out = distinctValuesOf(item, keys, out)
+		}
+		return out
+	case map[string]interface{}:
+		if len(keys) == 0 {
+			return append(out, v)
+		}
+		if item, ok := v[keys[0]]; ok {
+			return distinctValuesOf(item, keys[1:], out)
+		}
+		return out
+	case nil:
+		return out
+	}
+	if len(keys) == 0 {
+		return append(out, val)
+	}
+	return out
+}
+
+// helper function to parse DAS query along with its pipe, conditions of
+// DAS query are parsed by dasql parser while its pipe is parsed by
+// dasgoclient filters engine
+func parseDASQuery(query, inst string, daskeys []string) (dasql.DASQuery, Pipeline, string, string) {
+	conds, pipe := splitPipe(query)
+	var pipeline Pipeline
+	if pipe != "" {
+		var err error
+		if pipeline, err = parsePipeline(pipe); err != nil {
+			// position of the error within DAS query, the pipe starts after
+			// | character and its leading spaces
+			pos := pipeIndex(query) + 1
+			pos += len(query[pos:]) - len(strings.TrimLeft(query[pos:], " \t\n"))
+			if e, ok := err.(*PipeError); ok {
+				pos += e.Pos
+			}
+			idx := len(strings.Fields(query[:pos]))
+			msg := fmt.Sprintf("DAS QL ERROR, query=%s, idx=%d, msg=%v", query, idx, err)
+			return dasql.DASQuery{Query: query}, pipeline, msg, strings.Repeat("-", pos) + "^"
+		}
+	}
+	dasquery, err, posLine := dasql.Parse(conds, inst, daskeys)
+	if err != "" {
+		return dasquery, pipeline, err, posLine
+	}
+	for _, agg := range pipeline.Aggregators {
+		if agg[1] == "" && len(dasquery.Fields) > 0 {
+			agg[1] = dasquery.Fields[0]
+		}
+	}
+	dasquery.Query = query
+	dasquery.Pipe = pipe
+	dasquery.Filters = pipeline.Filters()
+	dasquery.Aggregators = [][]string{}
+	dasquery.Aggregators = append(dasquery.Aggregators, pipeline.Aggregators...)
+	return dasquery, pipeline, "", ""
+}
+
+// helper function to project generic DAS record into JSON object with
+// given attributes only, e.g. {"file":{"name":...,"size":...}}, attributes
+// with several values are represented as lists and missing ones as null
+func projectRecord(rec map[string]interface{}, attrs []string) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, attr := range attrs {
+		vals := uniqueValues(rec, attr)
+		var value interface{}
+		switch len(vals) {
+		case 0:
+		case 1:
+			value = vals[0]
+		default:
+			value = vals
+		}
+		keys := strings.Split(attr, ".")
+		m := out
+		for _, key := range keys[:len(keys)-1] {
+			if _, ok := m[key]; !ok {
+				m[key] = make(map[string]interface{})
+			}
+			if m, _ = m[key].(map[string]interface{}); m == nil {
+				break
+			}
+		}
+		if m != nil {
+			m[keys[len(keys)-1]] = value
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to create file DAS record
+func fileRecord(name string, size int64, blocks ...string) mongo.DASRecord {
+	var files []mongo.DASRecord
+	for _, blk := range blocks {
+		files = append(files, mongo.DASRecord{"name": name, "size": size, "block_name": blk})
+	}
+	das := mongo.DASRecord{"primary_key": "file.name", "services": []string{"dbs3:files"}}
+	return mongo.DASRecord{"das": das, "file": files, "qhash": "123"}
+}
+
+// helper function to get file names of DAS records
+func fileNames(records []mongo.DASRecord) []string {
+	var out []string
+	for _, rec := range records {
+		out = append(out, filteredValues(genericRecord(rec), "file.name")...)
+	}
+	return out
+}
+
+// TestParsePipeline tests parsing of DAS query pipe
+func TestParsePipeline(t *testing.T) {
+	assert := assert.New(t)
+	p, err := parsePipeline("grep file.name, file.size>1e9 and (file.name like '*RAW*' or not file.nevents) | sort -file.size, file.name:asc | unique | count")
+	assert.Nil(err)
+	assert.Equal(3, len(p.Stages))
+	assert.Equal([]string{"file.name", "file.size", "file.nevents"}, p.Attributes())
+	assert.Equal([]SortKey{{Attr: "file.size", Desc: true}, {Attr: "file.name"}}, p.Stages[1].Keys)
+	assert.Equal([][]string{{"count", ""}}, p.Aggregators)
+	assert.Equal(map[string][]string{
+		"grep":   {"file.name", "file.size", "file.nevents"},
+		"sort":   {"-file.size", "file.name"},
+		"unique": {"1"},
+	}, p.Filters())
+	assert.True(p.Sorted())
+
+	p, err = parsePipeline("sum(file.size), count(file.name)")
+	assert.Nil(err)
+	assert.Equal([][]string{{"sum", "file.size"}, {"count", "file.name"}}, p.Aggregators)
+	p, err = parsePipeline("grep file.size>0 | sum(file.size), count group by file.dataset, file.block_name")
+	assert.Nil(err)
+	assert.Equal([][]string{{"sum", "file.size"}, {"count", ""}}, p.Aggregators[:2])
+	assert.Equal([]string{"file.dataset", "file.block_name"}, p.GroupBy)
+
+	for _, pipe := range []string{"", "grep", "grep file.size>", "grep (file.size>1", "grep file.name ~ '[a'", "sort", "sum file.size", "count | grep file.name", "bogus", "grep file.name file.size", "sum(file.size) group file.name", "count group by", "count group by a | sum(b) group by c"} {
+		_, err = parsePipeline(pipe)
+		assert.NotNil(err, pipe)
+	}
+	query, pipe := splitPipe(`file dataset=/a/b/c | grep file.name ~ "a|b" || file.size>1`)
+	assert.Equal("file dataset=/a/b/c", query)
+	assert.Equal(`grep file.name ~ "a|b" || file.size>1`, pipe)
+}
+
+// TestPipelineApply tests filters of DAS query pipe
+func TestPipelineApply(t *testing.T) {
+	assert := assert.New(t)
+	records := []mongo.DASRecord{
+		fileRecord("/store/a/RAW/1.root", 2000000000, "/a/b/RAW#1"),
+		fileRecord("/store/a/RAW/2.root", 100, "/a/b/RAW#1"),
+		fileRecord("/store/a/AOD/3.root", 300, "/a/b/AOD#2", "/a/b/AOD#3"),
+		fileRecord("/store/a/AOD/3.root", 300, "/a/b/AOD#2"),
+	}
+	apply := func(pipe string) []mongo.DASRecord {
+		p, err := parsePipeline(pipe)
+		assert.Nil(err, pipe)
+		return p.Apply(records)
+	}
+	assert.Equal([]string{"/store/a/RAW/1.root"}, fileNames(apply("grep file.name, file.size>1e9")))
+	assert.Equal([]string{"/store/a/RAW/1.root", "/store/a/RAW/2.root"}, fileNames(apply("grep file.name like */RAW/*")))
+	assert.Equal([]string{"/store/a/AOD/3.root", "/store/a/AOD/3.root"}, fileNames(apply("grep file.name !~ RAW")))
+	assert.Equal([]string{"/store/a/RAW/2.root"}, fileNames(apply("grep file.size<1000 and not file.name ~ AOD")))
+	assert.Equal([]string{"/store/a/RAW/1.root", "/store/a/RAW/2.root"}, fileNames(apply("grep file.size=100 || file.size==2e9")))
+	// all elements of DAS record lists are matched
+	assert.Equal([]string{"/store/a/AOD/3.root"}, fileNames(apply("grep file.block_name=/a/b/AOD#3")))
+	assert.Equal([]string{"/store/a/RAW/2.root", "/store/a/AOD/3.root", "/store/a/AOD/3.root", "/store/a/RAW/1.root"}, fileNames(apply("sort file.size, -file.name")))
+	assert.Equal([]string{"/store/a/RAW/1.root", "/store/a/AOD/3.root", "/store/a/AOD/3.root", "/store/a/RAW/2.root"}, fileNames(apply("sort file.size:desc")))
+	assert.Equal([]string{"/store/a/RAW/1.root", "/store/a/RAW/2.root", "/store/a/AOD/3.root"}, fileNames(apply("unique")))
+	assert.Equal([]string{"/store/a/RAW/1.root", "/store/a/AOD/3.root", "/store/a/AOD/3.root"}, fileNames(apply("grep file.block_name | unique")))
+	assert.Equal(4, len(apply("grep file.name, file.nevents")))
+	assert.Equal(0, len(apply("grep file.nevents>0")))
+
+	grec := genericRecord(records[2])
+	assert.Equal([]string{"/a/b/AOD#2", "/a/b/AOD#3"}, filteredValues(grec, "file.block_name"))
+	assert.Equal([]string{"300"}, filteredValues(grec, "file.size"))
+	assert.Equal(0, len(filteredValues(grec, "file.nevents")))
+}
+
+// TestProjectRecord tests projection of DAS records into selected attributes
+func TestProjectRecord(t *testing.T) {
+	assert := assert.New(t)
+	rec := fileRecord("/store/a/AOD/3.root", 300, "/a/b/AOD#2", "/a/b/AOD#3")
+	out := projectRecord(genericRecord(rec), []string{"file.name", "file.size", "file.block_name", "file.nevents"})
+	data, err := json.Marshal(out)
+	assert.Nil(err)
+	assert.Equal(`{"file":{"block_name":["/a/b/AOD#2","/a/b/AOD#3"],"name":"/store/a/AOD/3.root","nevents":null,"size":300}}`, string(data))
+
+	out = projectRecord(genericRecord(rec), []string{"das.primary_key", "qhash"})
+	assert.Equal(map[string]interface{}{"das": map[string]interface{}{"primary_key": "file.name"}, "qhash": "123"}, out)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+)
+
+// helper function to extract values of given attributes (in a form of
+// jsonparser keys) from DAS record, missing attributes yield empty values
+func attributeValues(rec mongo.DASRecord, attrs [][]string) []string {
+	var out []string
+	rbytes, err := mongo.GetBytesFromDASRecord(rec)
+	if err != nil {
+		if utils.VERBOSE > 0 {
+			fmt.Printf("Fail to parse DAS record=%+v, error=%v\n", rec, err)
+		}
+		return out
+	}
+	for _, keys := range attrs {
+		val, _, _, err := jsonparser.Get(rbytes, keys...)
+		if err != nil {
+			if utils.VERBOSE > 1 {
+				fmt.Printf("Unable to extract attribute=%v, error=%v\n", keys, err)
+			}
+			out = append(out, "")
+			continue
+		}
+		out = append(out, string(val))
+	}
+	return out
+}
+
+// helper function to convert dotted attribute name, e.g. file.size,
+// into jsonparser keys of DAS record list, e.g. [file [0] size]
+func attributeKeys(attr string) []string {
+	var keys []string
+	for idx, val := range strings.Split(attr, ".") {
+		keys = append(keys, val)
+		if idx == 0 {
+			keys = append(keys, "[0]")
+		}
+	}
+	return keys
+}
+
+// helper function to convert DAS records into header and rows of values of
+// their attributes, it is used by CSV and table data-formats
+func tableRows(res Result, opts Options) ([]string, [][]string) {
+	dasquery := res.Query
+	var header []string
+	var rows [][]string
+	if len(dasquery.Aggregators) > 0 {
+		groupBy := res.Pipeline.GroupBy
+		header = append(append(header, groupBy...), "function", "key", "value")
+		for _, rec := range res.Records {
+			group, _ := rec["group"].(mongo.DASRecord)
+			var row []string
+			for _, attr := range groupBy {
+				row = append(row, fmt.Sprintf("%v", group[attr]))
+			}
+			row = append(row, fmt.Sprintf("%v", rec["function"]), fmt.Sprintf("%v", rec["key"]), formatAggregate(aggregatedValue(rec)))
+			rows = append(rows, row)
+		}
+	} else if filters, ok := dasquery.Filters["grep"]; ok && len(filters) > 0 {
+		header = filters
+		for _, rec := range res.Records {
+			if recordError(rec) != nil {
+				continue
+			}
+			rows = append(rows, projectRows(genericRecord(rec), filters, opts.Arrays)...)
+		}
+	} else {
+		for _, keys := range res.SelectKeys {
+			header = append(header, strings.Join(keys, "."))
+		}
+		header = utils.List2Set(header)
+		for _, rec := range res.Records {
+			if recordError(rec) != nil {
+				continue
+			}
+			rows = append(rows, projectRows(genericRecord(rec), header, opts.Arrays)...)
+		}
+	}
+	if res.Unique {
+		set := make(map[string][]string)
+		var keys []string
+		for _, row := range rows {
+			key := strings.Join(row, "\x00")
+			if _, ok := set[key]; !ok {
+				keys = append(keys, key)
+			}
+			set[key] = row
+		}
+		// keep order of records sorted by sort keys or aggregators
+		if !res.Sorted {
+			sort.SliceStable(keys, func(i, j int) bool {
+				return compareLines(keys[i], keys[j], "\x00") < 0
+			})
+		}
+		rows = nil
+		for _, k := range keys {
+			rows = append(rows, set[k])
+		}
+	}
+	return header, rows
+}
+
+// helper function to select rows of given page, i.e. starting at idx and
+// up to limit rows
+func pageRows(rows [][]string, idx, limit int) [][]string {
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(rows) {
+		return nil
+	}
+	rows = rows[idx:]
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// helper function to write DAS records in CSV data-format, it returns
+// number of written rows
+func writeCSV(w io.Writer, res Result, opts Options) int {
+	header, rows := tableRows(res, opts)
+	rows = pageRows(rows, opts.Idx, opts.Limit)
+	writer := csv.NewWriter(w)
+	writer.Write(header)
+	for _, row := range rows {
+		writer.Write(row)
+	}
+	writer.Flush()
+	return len(rows)
+}
+
+// helper function to return error of DAS record if any
+func recordError(rec mongo.DASRecord) interface{} {
+	das, ok := rec["das"].(mongo.DASRecord)
+	if !ok {
+		return nil
+	}
+	pkey, _ := das["primary_key"].(string)
+	lkey := strings.Split(pkey, ".")[0]
+	switch rrr := rec[lkey].(type) {
+	case []mongo.DASRecord:
+		for _, r := range rrr {
+			if r["error"] != nil {
+				return r["error"]
+			}
+		}
+	case []interface{}:
+		for _, r := range rrr {
+			if v, ok := r.(map[string]interface{}); ok && v["error"] != nil {
+				return v["error"]
+			}
+		}
+	}
+	return nil
+}
+
+// placeholder of DAS record attributes which are missing in the record
+var missingValue = "N/A"
+
+// supported representations of DAS record lists in text output
+var arrayModes = []string{"rows", "join"}
+
+// helper function to get values of dotted attribute of generic DAS record
+// for projection, lists of sub-records are walked through while lists of
+// plain values, e.g. lumi numbers, are kept as a single value
+func projectionValues(val interface{}, keys []string, out []interface{}) []interface{} {
+	switch v := val.(type) {
+	case []interface{}:
+		if len(keys) == 0 {
+			return append(out, v)
+		}
+		for _, item := range v {
+			out = projectionValues(item, keys, out)
+		}
+		return out
+	case map[string]interface{}:
+		if len(keys) == 0 {
+			return append(out, v)
+		}
+		if item, ok := v[keys[0]]; ok {
+			return projectionValues(item, keys[1:], out)
+		}
+		return out
+	case nil:
+		return out
+	}
+	if len(keys) == 0 {
+		return append(out, val)
+	}
+	return out
+}
+
+// helper function to get cell of projected attribute, i.e. its unique
+// values joined by comma or placeholder of missing attribute
+func projectionCell(val interface{}, keys []string) string {
+	var out []string
+	for _, v := range projectionValues(val, keys, nil) {
+		out = append(out, formatValue(v))
+	}
+	out = utils.List2Set(out)
+	if len(out) == 0 {
+		return missingValue
+	}
+	return strings.Join(out, ",")
+}
+
+// helper function to project generic DAS record into rows of given
+// attributes, every element of DAS record list, e.g. file sub-records
+// provided by different CMS data-services, yields its own row (rows mode)
+// or their values are joined into a single cell (join mode). Attributes of
+// the same list are taken from the same element while attributes of
+// different lists are combined.
+func projectRows(rec map[string]interface{}, attrs []string, mode string) [][]string {
+	// group attributes by their DAS record lists, e.g. file.name and
+	// file.size belong to file list
+	var lists []string
+	groups := make(map[string][]int)
+	for idx, attr := range attrs {
+		key := strings.Split(attr, ".")[0]
+		if _, ok := groups[key]; !ok {
+			lists = append(lists, key)
+		}
+		groups[key] = append(groups[key], idx)
+	}
+	rows := [][]string{make([]string, len(attrs))}
+	for _, key := range lists {
+		var elements []interface{}
+		switch v := rec[key].(type) {
+		case []interface{}:
+			elements = v
+		case nil:
+		default:
+			elements = []interface{}{v}
+		}
+		var tuples [][]string
+		var seen []string
+		for _, elem := range elements {
+			tuple := make([]string, len(groups[key]))
+			for i, idx := range groups[key] {
+				keys := strings.Split(attrs[idx], ".")[1:]
+				tuple[i] = projectionCell(elem, keys)
+			}
+			if tkey := strings.Join(tuple, "\x00"); !utils.InList(tkey, seen) {
+				seen = append(seen, tkey)
+				tuples = append(tuples, tuple)
+			}
+		}
+		if len(tuples) == 0 {
+			tuple := make([]string, len(groups[key]))
+			for i := range tuple {
+				tuple[i] = missingValue
+			}
+			tuples = append(tuples, tuple)
+		}
+		if mode == "join" && len(tuples) > 1 {
+			tuple := make([]string, len(groups[key]))
+			for i := range tuple {
+				var vals []string
+				for _, t := range tuples {
+					if t[i] != missingValue {
+						vals = append(vals, strings.Split(t[i], ",")...)
+					}
+				}
+				tuple[i] = missingValue
+				if vals = utils.List2Set(vals); len(vals) > 0 {
+					tuple[i] = strings.Join(vals, ",")
+				}
+			}
+			tuples = [][]string{tuple}
+		}
+		// combine rows with values of the list
+		var out [][]string
+		for _, row := range rows {
+			for _, tuple := range tuples {
+				r := append([]string{}, row...)
+				for i, idx := range groups[key] {
+					r[idx] = tuple[i]
+				}
+				out = append(out, r)
+			}
+		}
+		rows = out
+	}
+	return rows
+}
+
+// helper function to get missing attributes of projected rows
+func missingAttributes(rows [][]string, attrs []string) []string {
+	var out []string
+	for _, row := range rows {
+		for idx, val := range row {
+			if val == missingValue && !utils.InList(attrs[idx], out) {
+				out = append(out, attrs[idx])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProjectRows tests projection of DAS records into rows of attributes
+func TestProjectRows(t *testing.T) {
+	assert := assert.New(t)
+	// file record provided by two CMS data-services along with its lumis
+	records, err := readRecords(strings.NewReader(`{
+"das":{"primary_key":"file.name"},
+"file":[{"name":"/store/a.root","size":10},{"name":"/store/a.root","size":11,"adler32":"abc"}],
+"run":[{"run_number":1},{"run_number":2}],
+"lumi":[{"number":[[1,3],[5,5]]}]
+}`))
+	assert.Nil(err)
+	rec := records[0]
+	attrs := []string{"file.name", "file.size", "run.run_number", "lumi.number"}
+	assert.Equal([][]string{
+		{"/store/a.root", "10", "1", "[[1,3],[5,5]]"},
+		{"/store/a.root", "10", "2", "[[1,3],[5,5]]"},
+		{"/store/a.root", "11", "1", "[[1,3],[5,5]]"},
+		{"/store/a.root", "11", "2", "[[1,3],[5,5]]"},
+	}, projectRows(rec, attrs, "rows"))
+	assert.Equal([][]string{{"/store/a.root", "10,11", "1,2", "[[1,3],[5,5]]"}}, projectRows(rec, attrs, "join"))
+
+	// missing attributes are reported explicitly
+	attrs = []string{"file.name", "file.adler32", "block.name"}
+	rows := projectRows(rec, attrs, "rows")
+	assert.Equal([][]string{{"/store/a.root", "N/A", "N/A"}, {"/store/a.root", "abc", "N/A"}}, rows)
+	assert.Equal([]string{"file.adler32", "block.name"}, missingAttributes(rows, attrs))
+	assert.Equal([][]string{{"/store/a.root", "abc", "N/A"}}, projectRows(rec, attrs, "join"))
+
+	// text output of DAS records
+	dasrecords := []mongo.DASRecord{fileRecord("/store/b.root", 20, "/a/b/c#1", "/a/b/c#2")}
+	keys := [][]string{{"file", "name"}, {"file", "block_name"}}
+	assert.Equal([]string{"/store/b.root /a/b/c#1", "/store/b.root /a/b/c#2"}, getRecords(dasrecords, keys, " ", "rows", false))
+	assert.Equal([]string{"/store/b.root /a/b/c#1,/a/b/c#2"}, getRecords(dasrecords, keys, " ", "join", false))
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/utils"
+)
+
+// DAS QL special keys and operators, see dasql module
+var dasSpecialKeys = []string{"date", "system", "instance", "detail"}
+var dasKeyOperators = []string{"=", "<", ">", "!", "in", "between", "last"}
+
+// pattern of DAS QL error message produced by dasql parser
+var qlErrorPattern = regexp.MustCompile(`^DAS QL ERROR, query=(.*), idx=[0-9]+, msg=(.*)$`)
+
+// LintQuery represents DAS query to lint along with its source location
+type LintQuery struct {
+	Source string `json:"source"`
+	Query  string `json:"query"`
+}
+
+// LintResult represents outcome of DAS query validation
+type LintResult struct {
+	LintQuery
+	ECode       int                 `json:"ecode"`
+	Error       string              `json:"error,omitempty"`
+	Position    string              `json:"position,omitempty"`
+	Relaxed     string              `json:"relaxed_query,omitempty"`
+	Suggestions map[string][]string `json:"suggestions,omitempty"`
+}
+
+// helper function to read DAS queries, one per line, empty lines and
+// comments are skipped
+func readQueries(r io.Reader, source string) ([]LintQuery, error) {
+	var out []LintQuery
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line += 1
+		query := strings.TrimSpace(scanner.Text())
+		if query == "" || strings.HasPrefix(query, "#") {
+			continue
+		}
+		out = append(out, LintQuery{Source: fmt.Sprintf("%s:%d", source, line), Query: query})
+	}
+	return out, scanner.Err()
+}
+
+// helper function to calculate edit (Levenshtein) distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// helper function to split conditions of DAS query into words along with
+// their byte offsets, operators and separators are words on their own
+func queryWords(query string) ([]string, []int) {
+	if idx := pipeIndex(query); idx >= 0 {
+		query = query[:idx]
+	}
+	var words []string
+	var offsets []int
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, query[start:end])
+			offsets = append(offsets, start)
+			start = -1
+		}
+	}
+	for idx, r := range query {
+		switch {
+		case strings.ContainsRune("=<>!,[]", r):
+			flush(idx)
+			words = append(words, string(r))
+			offsets = append(offsets, idx)
+		case unicode.IsSpace(r):
+			flush(idx)
+		case start < 0:
+			start = idx
+		}
+	}
+	flush(len(query))
+	return words, offsets
+}
+
+// helper function to find keys used in DAS query, i.e. select keys and
+// keys of query conditions, along with their byte offsets
+func queryKeyOffsets(query string) ([]string, []int) {
+	if strings.HasPrefix(strings.TrimSpace(query), "/") {
+		// dataset, block or file name shortcut
+		return nil, nil
+	}
+	words, offsets := queryWords(query)
+	var keys []string
+	var pos []int
+	spec := false
+	for idx, word := range words {
+		next := ""
+		if idx+1 < len(words) {
+			next = words[idx+1]
+		}
+		if utils.InList(next, dasKeyOperators) && !utils.InList(word, dasKeyOperators) {
+			keys = append(keys, word)
+			pos = append(pos, offsets[idx])
+			spec = true
+		} else if !spec && word != "," {
+			keys = append(keys, word)
+			pos = append(pos, offsets[idx])
+		}
+	}
+	return keys, pos
+}
+
+// helper function to find keys used in DAS query, i.e. select keys and
+// keys of query conditions
+func queryKeys(query string) []string {
+	keys, _ := queryKeyOffsets(query)
+	return keys
+}
+
+// helper function to find byte offsets of given keys in DAS query, keys
+// which are not present in DAS query, e.g. dataset of /a/b/c shortcut,
+// point to its first word
+func keyOffsets(query string, keys []string) []int {
+	var out []int
+	qkeys, offsets := queryKeyOffsets(query)
+	for _, key := range keys {
+		found := false
+		for idx, k := range qkeys {
+			if k == key {
+				out = append(out, offsets[idx])
+				found = true
+			}
+		}
+		if !found {
+			out = append(out, len(query)-len(strings.TrimLeft(query, " \t")))
+		}
+	}
+	return out
+}
+
+// helper function to build position line which points to given byte
+// offsets of DAS query, e.g. -----^
+func positionLine(offsets []int) string {
+	if len(offsets) == 0 {
+		return ""
+	}
+	line := []byte(strings.Repeat("-", slices.Max(offsets)+1))
+	for _, pos := range offsets {
+		line[pos] = '^'
+	}
+	return string(line)
+}
+
+// helper function to validate patterns of DAS query conditions, it returns
+// keys of conditions which fail validation, in alphabetical order, along
+// with validation error of the first one
+func invalidSpecs(dasquery dasql.DASQuery) ([]string, error) {
+	var keys []string
+	var err error
+	for _, key := range sortedKeys(dasquery.Spec) {
+		query := dasquery
+		query.Spec = map[string]interface{}{key: dasquery.Spec[key]}
+		if e := dasql.ValidateDASQuerySpecs(query); e != nil {
+			keys = append(keys, key)
+			if err == nil {
+				err = e
+			}
+		}
+	}
+	return keys, err
+}
+
+// helper function to find unknown keys of DAS query and suggest their
+// corrections based on edit distance to known DAS keys
+func suggestKeys(query string, daskeys []string) ([]string, map[string][]string) {
+	var keys []string
+	keys = append(keys, dasSpecialKeys...)
+	keys = append(keys, daskeys...)
+	var unknown []string
+	out := make(map[string][]string)
+	for _, key := range queryKeys(query) {
+		if utils.InList(key, keys) || utils.InList(key, unknown) {
+			continue
+		}
+		unknown = append(unknown, key)
+		best := -1
+		var matches []string
+		for _, k := range keys {
+			dist := editDistance(key, k)
+			if dist > 2 || dist >= len(key) {
+				continue
+			}
+			if best < 0 || dist < best {
+				best = dist
+				matches = []string{k}
+			} else if dist == best {
+				matches = append(matches, k)
+			}
+		}
+		if len(matches) > 0 {
+			sort.Strings(matches)
+			out[key] = matches
+		}
+	}
+	return unknown, out
+}
+
+// helper function to parse DAS query, malformed queries may cause dasql
+// parser panic which we report as parser error
+func parseQuery(query string, daskeys []string) (dasquery dasql.DASQuery, err, posLine string) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Sprintf("unable to parse DAS query: %v", r)
+		}
+	}()
+	dasquery, _, err, posLine = parseDASQuery(query, "", daskeys)
+	return dasquery, err, posLine
+}
+
+// helper function to lint DAS query
+func lintQuery(q LintQuery, daskeys []string) LintResult {
+	res := LintResult{LintQuery: q}
+	dasquery, err, posLine := parseQuery(prepareQuery(q.Query), daskeys)
+	if err != "" {
+		res.ECode = utils.DASParserError
+		res.Error = err
+		res.Position = posLine
+		if posLine == "" {
+			// parser failure without position, e.g. its panic
+			res.Position = positionLine([]int{len(q.Query) - len(strings.TrimLeft(q.Query, " \t"))})
+		}
+		if arr := qlErrorPattern.FindStringSubmatch(err); len(arr) == 3 {
+			res.Relaxed = arr[1]
+			res.Error = arr[2]
+		}
+		_, res.Suggestions = suggestKeys(q.Query, daskeys)
+		return res
+	}
+	// dasql parser silently drops unknown select keys, e.g. fiel,run
+	if unknown, suggestions := suggestKeys(q.Query, daskeys); len(unknown) > 0 {
+		res.ECode = utils.DASParserError
+		res.Error = fmt.Sprintf("Wrong DAS key: %s", strings.Join(unknown, ", "))
+		res.Position = positionLine(keyOffsets(q.Query, unknown))
+		res.Suggestions = suggestions
+		return res
+	}
+	if keys, e := invalidSpecs(dasquery); e != nil {
+		res.ECode = utils.DASValidationError
+		res.Error = e.Error()
+		res.Position = positionLine(keyOffsets(q.Query, keys))
+	}
+	return res
+}
+
+// helper function to print lint result
+func (r LintResult) Print(w io.Writer) {
+	if r.ECode == 0 {
+		if utils.VERBOSE > 0 {
+			fmt.Fprintf(w, "%s: OK: %s\n", r.Source, r.Query)
+		}
+		return
+	}
+	fmt.Fprintf(w, "%s: %s: %s\n", r.Source, dasExitCodeName(r.ECode), r.Error)
+	if r.Relaxed != "" {
+		fmt.Fprintf(w, "    %s\n    %s\n", r.Relaxed, r.Position)
+	} else {
+		fmt.Fprintf(w, "    %s\n", r.Query)
+		if r.Position != "" {
+			fmt.Fprintf(w, "    %s\n", r.Position)
+		}
+	}
+	for _, key := range sortedKeys(r.Suggestions) {
+		fmt.Fprintf(w, "    did you mean %s instead of %s?\n", strings.Join(r.Suggestions[key], " or "), key)
+	}
+}
+
+// helper function to lint DAS queries provided via command line arguments,
+// input file or stdin (-input -), it returns DAS exit code
+func lint(args []string, input string, jsonout bool) int {
+	var queries []LintQuery
+	for idx, q := range args {
+		queries = append(queries, LintQuery{Source: fmt.Sprintf("arg:%d", idx+1), Query: q})
+	}
+	// stdin is read only on explicit request, e.g. to not block in CI
+	if input == "" && len(args) == 0 {
+		fmt.Println("ERROR: please provide DAS queries as arguments or via -input option (use -input - to read them from stdin)")
+		return utils.DASQueryError
+	}
+	if input == "-" {
+		qs, err := readQueries(os.Stdin, "stdin")
+		if err != nil {
+			fmt.Println("ERROR: unable to read DAS queries", err)
+			return utils.DASQueryError
+		}
+		queries = append(queries, qs...)
+	} else if input != "" {
+		file, err := os.Open(input)
+		if err != nil {
+			fmt.Println("ERROR: unable to read DAS queries", err)
+			return utils.DASQueryError
+		}
+		qs, err := readQueries(file, input)
+		file.Close()
+		if err != nil {
+			fmt.Println("ERROR: unable to read DAS queries", err)
+			return utils.DASQueryError
+		}
+		queries = append(queries, qs...)
+	}
+	dmaps, err := loadLocalDASMaps()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return utils.DASQueryError
+	}
+	daskeys := dmaps.DASKeys()
+
+	// dasql parser logs its errors, we report them ourselves
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var results []LintResult
+	ecode := 0
+	nerrors := 0
+	for _, q := range queries {
+		res := lintQuery(q, daskeys)
+		if res.ECode != 0 {
+			nerrors += 1
+			// parser errors take precedence over validation ones
+			if ecode != utils.DASParserError {
+				ecode = res.ECode
+			}
+		}
+		results = append(results, res)
+	}
+	if jsonout {
+		if results == nil {
+			results = []LintResult{}
+		}
+		printJSON(results)
+		return ecode
+	}
+	for _, res := range results {
+		res.Print(os.Stdout)
+	}
+	fmt.Printf("%d queries, %d errors\n", len(queries), nerrors)
+	return ecode
+}
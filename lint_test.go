@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEditDistance tests edit distance function
+func TestEditDistance(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(0, editDistance("file", "file"))
+	assert.Equal(1, editDistance("datset", "dataset"))
+	assert.Equal(2, editDistance("fiel", "file"))
+	assert.Equal(4, editDistance("", "site"))
+}
+
+// TestLintQuery tests validation of DAS queries
+func TestLintQuery(t *testing.T) {
+	assert := assert.New(t)
+	var dmaps dasmaps.DASMaps
+	dmaps.ReadMapFile("testdata/das_maps.js")
+	daskeys := dmaps.DASKeys()
+
+	assert.Equal([]string{"file", "run", "dataset"}, queryKeys("file,run dataset=/a/b/c | grep file.name"))
+	assert.Equal([]string{"run", "dataset"}, queryKeys("run in [1,2] dataset=/a/b/c"))
+
+	res := lintQuery(LintQuery{Query: "file dataset=/a/b/c"}, daskeys)
+	assert.Equal(0, res.ECode)
+
+	res = lintQuery(LintQuery{Query: "file dataset=/a/b/c sitee=T1_*"}, daskeys)
+	assert.Equal(utils.DASParserError, res.ECode)
+	assert.Equal("Wrong DAS key: sitee", res.Error)
+	assert.Equal([]string{"site"}, res.Suggestions["sitee"])
+	assert.True(strings.HasSuffix(res.Position, "^"))
+
+	res = lintQuery(LintQuery{Query: "file datset=/a/b/c"}, daskeys)
+	assert.Equal(utils.DASParserError, res.ECode)
+	assert.Equal([]string{"dataset"}, res.Suggestions["datset"])
+	assert.True(strings.HasSuffix(res.Position, "^"))
+
+	res = lintQuery(LintQuery{Query: "fiel,run dataset=/a/b/c"}, daskeys)
+	assert.Equal(utils.DASParserError, res.ECode)
+	assert.Contains(res.Suggestions["fiel"], "file")
+	assert.Equal("^", res.Position)
+
+	// position line points to every unknown key and invalid condition
+	res = lintQuery(LintQuery{Query: "fiel dataset=/a/b/c"}, daskeys)
+	assert.Equal("Wrong DAS key: fiel", res.Error)
+	assert.Equal("^", res.Position)
+	res = lintQuery(LintQuery{Query: "file,lumii,runn dataset=/a/b/c"}, daskeys)
+	assert.Equal("-----^-----^", res.Position)
+	res = lintQuery(LintQuery{Query: "file dataset=/a/b/c site=T3_*"}, daskeys)
+	assert.Equal(utils.DASValidationError, res.ECode)
+	assert.Equal("Validation error: unmatched site pattern", res.Error)
+	assert.Equal("--------------------^", res.Position)
+	assert.Equal([]int{2}, keyOffsets("  /a/b/c", []string{"dataset"}))
+	var buf strings.Builder
+	res.Print(&buf)
+	assert.Equal(": DAS validation error: Validation error: unmatched site pattern\n    file dataset=/a/b/c site=T3_*\n    --------------------^\n", buf.String())
+
+	res = lintQuery(LintQuery{Query: "file dataset=/a/b/c run in [1,2"}, daskeys)
+	assert.Equal(utils.DASParserError, res.ECode)
+
+	res = lintQuery(LintQuery{Query: "dataset=abc*"}, daskeys)
+	assert.Equal(utils.DASValidationError, res.ECode)
+
+	// errors of DAS query pipe point to the token which caused them
+	for query, token := range map[string]string{
+		"file dataset=/a/b/c | grepp file.name":              "grepp",
+		"file dataset=/a/b/c | grep file.name, = x":          "= x",
+		"file dataset=/a/b/c | grep file.name ~ '[a'":        "'[a'",
+		"file dataset=/a/b/c | sort file.size | sum(file.x":  "",
+		"file dataset=/a/b/c |  sum(file.size) group file.x": "file.x",
+	} {
+		res = lintQuery(LintQuery{Query: query}, daskeys)
+		assert.Equal(utils.DASParserError, res.ECode, query)
+		assert.NotEqual("", res.Position, query)
+		pos := len(res.Position) - 1
+		assert.True(strings.HasPrefix(res.Relaxed[pos:], token), query+": "+res.Relaxed[pos:])
+	}
+	res = lintQuery(LintQuery{Query: "file dataset=/a/b/c | grep file.name="}, daskeys)
+	assert.Equal(len(res.Relaxed), len(res.Position)-1)
+
+	// stdin is read only if it is requested explicitly
+	assert.Equal(utils.DASQueryError, lint(nil, "", false))
+
+	queries, err := readQueries(strings.NewReader("# comment\n\ndataset=/a/b/c\n"), "stdin")
+	assert.Nil(err)
+	assert.Equal([]LintQuery{{Source: "stdin:3", Query: "dataset=/a/b/c"}}, queries)
+}
@@ -6,9 +6,12 @@ package main
 //
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 	"reflect"
@@ -17,7 +20,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/buger/jsonparser"
 	"github.com/dmwm/das2go/das"
 	"github.com/dmwm/das2go/dasmaps"
 	"github.com/dmwm/das2go/dasql"
@@ -28,18 +30,42 @@ import (
 )
 
 func main() {
+	// dasgoclient supports sub-commands, e.g. dasgoclient serve -listen=:8217
+	var command, subcommand string
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	// maps and config commands have their own sub-commands, e.g.
+	// dasgoclient maps list
+	if (command == "maps" || command == "config") && len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		subcommand = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
 	var query string
 	flag.StringVar(&query, "query", "", "DAS query to run")
 	var jsonout bool
-	flag.BoolVar(&jsonout, "json", false, "Return results in JSON data-format")
+	flag.BoolVar(&jsonout, "json", false, "Return results in JSON data-format, also applies to -daskeys, -exitCodes and -examples")
 	var format string
-	flag.StringVar(&format, "format", "", "Compatibility option with python das_client, use json to get das_client behavior")
+	flag.StringVar(&format, "format", "", "Compatibility option with python das_client, use json to get das_client behavior, or use ndjson, csv, table or parquet (requires -o)")
 	var limit int
 	flag.IntVar(&limit, "limit", 0, "Compatibility option with python das_client")
+	var arrays string
+	flag.StringVar(&arrays, "arrays", "rows", "Show elements of DAS record lists as separate rows or join their values into a single cell (rows or join)")
+	var output string
+	flag.StringVar(&output, "o", "", "Write results atomically to given file instead of stdout, .gz and .zst files are compressed, metadata are written to <file>.meta.json")
+	var tmpl string
+	flag.StringVar(&tmpl, "template", "", "Go template to format every DAS record, e.g. '{{.file.name}} {{.file.size | human}}'")
+	var sortKeys string
+	flag.StringVar(&sortKeys, "sort", "", "Sort results by attributes of DAS records, e.g. file.size:desc,file.name (default is order of primary key)")
 	var idx int
 	flag.IntVar(&idx, "idx", 0, "Compatibility option with python das_client")
 	var host string
 	flag.StringVar(&host, "host", "https://cmsweb.cern.ch", "Specify hostname to talk to")
+	var env string
+	flag.StringVar(&env, "env", "", "Specify environment, one of prod, preprod, testbed or local (default is chosen by -host)")
+	var serviceUrl string
+	flag.StringVar(&serviceUrl, "serviceUrl", "", "base url of CMS data-services, e.g. dbs3=http://localhost:8989,rucio=https://rucio.example.com")
 	var threshold int
 	flag.IntVar(&threshold, "threshold", 0, "Compatibility option with python das_client, has no effect")
 	var sep string
@@ -52,8 +78,10 @@ func main() {
 	flag.StringVar(&token, "token", "", "Specify location of token file")
 	var verbose int
 	flag.IntVar(&verbose, "verbose", 0, "Verbose level, support 0,1,2")
-	var examples bool
-	flag.BoolVar(&examples, "examples", false, "Show examples of supported DAS queries")
+	var examples examplesFlag
+	flag.Var(&examples, "examples", "Show examples of supported DAS queries, use -examples=<keyword> to search them")
+	var examplesDir string
+	flag.StringVar(&examplesDir, "examplesDir", "", "Specify directory with DAS query examples to use instead of embedded ones")
 	var dnsCache bool
 	flag.BoolVar(&dnsCache, "dnsCache", false, "use local DNS cache")
 	var noKeepAlive bool
@@ -76,8 +104,42 @@ func main() {
 	flag.IntVar(&urlQueuelimit, "urlQueuelimit", 100, "url queue limit (number of concurrent calls)")
 	var funcProfile string
 	flag.StringVar(&funcProfile, "funcProfile", "", "Specify location of function profile file")
+	var otlp string
+	flag.StringVar(&otlp, "otlp", "", "OTLP collector url or file name to export trace spans (default is OTEL_EXPORTER_OTLP_ENDPOINT)")
+	var metrics string
+	flag.StringVar(&metrics, "metrics", "", "address of Prometheus /metrics end-point, e.g. :9217")
+	var stats bool
+	flag.BoolVar(&stats, "stats", false, "Show per-query timing and HTTP metrics (embedded in JSON with -format=json, otherwise printed on stderr)")
+	var listen string
+	flag.StringVar(&listen, "listen", ":8217", "address of dasgoclient server (serve command)")
+	var maxQueries int
+	flag.IntVar(&maxQueries, "maxQueries", 10, "number of concurrent DAS queries (serve command)")
+	var queryTimeout int
+	flag.IntVar(&queryTimeout, "queryTimeout", 300, "timeout of DAS query in seconds (serve command)")
+	var input string
+	flag.StringVar(&input, "input", "", "file with DAS queries, one per line, use - for stdin (lint command)")
+	var mapsSource string
+	flag.StringVar(&mapsSource, "mapsSource", dasMapsSource, "url or file name of DAS maps to fetch (maps command)")
+	var diffA string
+	flag.StringVar(&diffA, "a", "", "DAS query condition of first result, e.g. instance=prod/global (diff command)")
+	var diffB string
+	flag.StringVar(&diffB, "b", "", "DAS query condition of second result, e.g. instance=int/global (diff command)")
+	var crosscheck bool
+	flag.BoolVar(&crosscheck, "crosscheck", false, "Run DAS query against every CMS data-service which can answer it and report discrepancies of their results")
+	var explain bool
+	flag.BoolVar(&explain, "explain", false, "Show CMS data-services and urls which will be used to answer DAS query without calling them")
+	var rateLimit string
+	flag.StringVar(&rateLimit, "rateLimit", "", "rate limit per service or host in requests per second and burst, e.g. dbs=10:20,rucio=50")
 	flag.Usage = func() {
 		fmt.Println("Usage: dasgoclient [options]")
+		fmt.Println("       dasgoclient serve [options]")
+		fmt.Println("       dasgoclient shell [options]")
+		fmt.Println("       dasgoclient diff -query=<query> -a=<condition> -b=<condition> [options]")
+		fmt.Println("       dasgoclient diff [options] <file a> <file b>")
+		fmt.Println("       dasgoclient lint [options] [queries]")
+		fmt.Println("       dasgoclient config show [options]")
+		fmt.Println("       dasgoclient maps list|validate|diff|fetch|info|check [options] [args]")
+		fmt.Println("       dasgoclient completion bash|zsh|fish")
 		flag.PrintDefaults()
 		fmt.Println("Examples:")
 		fmt.Println("\t# get results")
@@ -88,7 +150,19 @@ func main() {
 		fmt.Println("\tdasgoclient -query=\"file dataset=/ZMM/Summer11-DESIGN42_V11_428_SLHC1-v1/GEN-SIM system=rucio\" -json")
 	}
 	mode := flag.String("profileMode", "", "enable profiling mode, one of [cpu, mem, block]")
+	// defaults of flags are taken from configuration file and environment
+	config, err := loadConfig(configFile())
+	if err != nil {
+		fmt.Println("ERROR: unable to load configuration", err)
+		os.Exit(utils.DASQueryError)
+	}
+	sources, err := config.Apply(flag.CommandLine, os.Environ())
+	if err != nil {
+		fmt.Println("ERROR: unable to load configuration", err)
+		os.Exit(utils.DASQueryError)
+	}
 	flag.Parse()
+	commandLineSources(flag.CommandLine, sources)
 	switch *mode {
 	case "cpu":
 		defer profile.Start(profile.CPUProfile, profile.ProfilePath(".")).Stop()
@@ -111,48 +185,170 @@ func main() {
 	} else {
 		utils.KEEP_ALIVE = true
 	}
-	services.FrontendURL = host
+	// explicit -host option takes precedence over host of the environment
+	if env != "" && sources["host"] == "default" {
+		host = ""
+	}
+	envProfile, err := NewProfile(env, host, serviceUrl)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(utils.DASQueryError)
+	}
+	envProfile.Setup()
 	utils.CLIENT_VERSION = "{{VERSION}}"
 	utils.TLSCertsRenewInterval = 600 * time.Second
 	if token == "" {
 		// check the BEARER_TOKEN or BEARER_TOKEN_FILE env
 		if os.Getenv("BEARER_TOKEN") != "" {
 			token = os.Getenv("BEARER_TOKEN")
+			sources["token"] = "environment BEARER_TOKEN"
 		} else if os.Getenv("BEARER_TOKEN_FILE") != "" {
 			token = os.Getenv("BEARER_TOKEN_FILE")
+			sources["token"] = "environment BEARER_TOKEN_FILE"
 		}
 	}
 	utils.Token = token
 	if funcProfile != "" {
 		utils.InitFunctionProfiler(funcProfile)
 	}
-	checkX509()
+	if !utils.InList(arrays, arrayModes) {
+		fmt.Printf("ERROR: unsupported arrays option %s, use one of %s\n", arrays, strings.Join(arrayModes, ", "))
+		os.Exit(utils.DASQueryError)
+	}
+	sortBy, err := parseSortOption(sortKeys)
+	if err != nil {
+		fmt.Println("ERROR: unable to parse sort option:", err)
+		os.Exit(utils.DASQueryError)
+	}
+	if strings.ToLower(format) == "parquet" && output == "" {
+		fmt.Println("ERROR: parquet format requires -o option")
+		os.Exit(utils.DASQueryError)
+	}
+	if _, err := parseTemplate(tmpl); err != nil {
+		fmt.Println("ERROR: unable to parse template option:", err)
+		os.Exit(utils.DASQueryError)
+	}
+	if rateLimit != "" {
+		limiter, err := NewRateLimiter(rateLimit)
+		if err != nil {
+			fmt.Println("ERROR: unable to parse rateLimit option:", err)
+			os.Exit(utils.DASQueryError)
+		}
+		rateLimiter = limiter
+	}
+	if stats {
+		queryStats = NewQueryStats()
+	}
+	if metrics != "" {
+		// metrics end-point of one-shot query would go away along with
+		// the process before it can be scraped
+		if command != "serve" && command != "shell" {
+			fmt.Println("ERROR: -metrics option is only supported by serve and shell commands")
+			os.Exit(utils.DASQueryError)
+		}
+		dasMetrics = NewMetrics()
+		startMetricsServer(metrics)
+	}
+	if otlp == "" {
+		otlp = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if otlp != "" {
+		// parent trace-context can be passed via TRACEPARENT environment
+		tracer = NewTracer(otlp, os.Getenv("TRACEPARENT"))
+	}
+	// informational options do not talk to CMS data-services and do not
+	// require user credentials
+	offline := utils.InList(command, []string{"completion", "config", "lint", "maps"})
+	if command == "diff" && flag.NArg() > 0 {
+		// comparison of saved results
+		offline = true
+	}
+	if !offline && !examples.Enabled && !version && !exitCodes {
+		checkX509()
+	}
 	if verbose > 0 {
-		fmt.Println("DBSUrl: ", services.DBSUrl("prod/global"))
+		fmt.Println("Environment: ", envProfile)
+		fmt.Println("DBSUrl: ", services.DBSUrl(envProfile.Instance))
 		fmt.Println("SitedbUrl: ", services.SitedbUrl())
 		fmt.Println("CricUrl w/ site API: ", services.CricUrl("site"))
 		fmt.Println("RucioUrl: ", services.RucioUrl())
 		fmt.Println("RucioAuthUrl: ", utils.RucioAuth.Url())
 	}
-	if examples {
-		showExamples()
+	opts := Options{
+		JSON:      jsonout,
+		Sep:       sep,
+		Unique:    unique,
+		Format:    format,
+		Profile:   envProfile,
+		Idx:       idx,
+		Limit:     limit,
+		Aggregate: aggregate,
+		NoDbsAgg:  noDbsAgg,
+		Explain:   explain,
+		Arrays:    arrays,
+		Sort:      sortBy,
+		Template:  tmpl,
+		Output:    output,
+	}
+	if command != "" {
+		switch command {
+		case "serve":
+			if utils.UrlQueueLimit > 0 {
+				utils.Init()
+			}
+			serve(listen, opts, maxQueries, time.Duration(queryTimeout)*time.Second)
+		case "shell":
+			if utils.UrlQueueLimit > 0 {
+				utils.Init()
+			}
+			shell(opts)
+		case "lint":
+			exit(lint(flag.Args(), input, jsonout))
+		case "config":
+			if subcommand != "show" {
+				fmt.Println("Usage: dasgoclient config show [options]")
+				os.Exit(utils.DASQueryError)
+			}
+			showConfig(os.Stdout, flag.CommandLine, config, sources, jsonout)
+		case "diff":
+			if utils.UrlQueueLimit > 0 && !offline {
+				utils.Init()
+			}
+			exit(diff(flag.Args(), query, diffA, diffB, opts))
+		case "maps":
+			exit(maps(subcommand, flag.Args(), mapsSource, jsonout))
+		case "completion":
+			if flag.NArg() != 1 {
+				fmt.Println("Usage: dasgoclient completion bash|zsh|fish")
+				os.Exit(utils.DASQueryError)
+			}
+			if err := completion(os.Stdout, flag.Arg(0)); err != nil {
+				fmt.Println("ERROR:", err)
+				os.Exit(utils.DASQueryError)
+			}
+		default:
+			fmt.Println("ERROR: unknown command", command)
+			flag.Usage()
+			os.Exit(utils.DASQueryError)
+		}
+	} else if examples.Enabled {
+		showExamples(jsonout, examplesDir, examples.Keyword)
 	} else if version {
 		fmt.Println(info())
 	} else if daskeys {
-		showDASKeys()
+		showDASKeys(jsonout)
 	} else if exitCodes {
-		showDASExitCodes()
+		showDASExitCodes(jsonout)
 	} else {
-		if strings.Contains(query, "|") && !strings.Contains(query, "detail") {
-			// for filters and aggregators we need to use detail=true flag
-			query = strings.Replace(query, "|", " detail=true |", 1)
-		}
 		if utils.UrlQueueLimit > 0 {
 			// initialize das2go utils which will spawn goroutine for
 			// queueing the HTTP calls
 			utils.Init()
 		}
-		process(query, jsonout, sep, unique, format, host, idx, limit, aggregate, noDbsAgg)
+		if crosscheck {
+			exit(crossCheck(prepareQuery(query), opts, loadDASMaps(opts.Profile)))
+		}
+		process(prepareQuery(query), opts)
 	}
 	os.Exit(0)
 }
@@ -201,26 +397,57 @@ func checkDASrecords(dasrecords []mongo.DASRecord) (int, string) {
 	return 0, ""
 }
 
-func showDASExitCodes() {
+// DASExitCode represents DAS exit code, its name and description
+type DASExitCode struct {
+	Code        int    `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// global table of DAS exit codes
+var dasExitCodes = []DASExitCode{
+	{utils.DASServerError, utils.DASServerErrorName, "generic DAS error, e.g. upstream data-service returned error without specific code"},
+	{utils.DBSError, utils.DBSErrorName, "DBS data-service returned an error"},
+	{utils.PhedexError, utils.PhedexErrorName, "PhEDEx data-service returned an error"},
+	{utils.RucioError, utils.RucioErrorName, "Rucio data-service returned an error"},
+	{utils.DynamoError, utils.DynamoErrorName, "Dynamo data-service returned an error"},
+	{utils.ReqMgrError, utils.ReqMgrErrorName, "ReqMgr data-service returned an error"},
+	{utils.RunRegistryError, utils.RunRegistryErrorName, "RunRegistry data-service returned an error"},
+	{utils.McMError, utils.McMErrorName, "McM data-service returned an error"},
+	{utils.DashboardError, utils.DashboardErrorName, "Dashboard data-service returned an error"},
+	{utils.SiteDBError, utils.SiteDBErrorName, "SiteDB data-service returned an error"},
+	{utils.CRICError, utils.CRICErrorName, "CRIC data-service returned an error"},
+	{utils.CondDBError, utils.CondDBErrorName, "CondDB data-service returned an error"},
+	{utils.CombinedError, utils.CombinedErrorName, "combination of DBS and Rucio results failed"},
+	{utils.MongoDBError, utils.MongoDBErrorName, "DAS cache (MongoDB) error"},
+	{utils.DASProxyError, utils.DASProxyErrorName, "neither X509 proxy, X509 key/cert nor token is available"},
+	{utils.DASQueryError, utils.DASQueryErrorName, "DAS query can't be processed, e.g. no CMS data-service can answer it"},
+	{utils.DASParserError, utils.DASParserErrorName, "DAS query has invalid syntax or unknown DAS key"},
+	{utils.DASValidationError, utils.DASValidationErrorName, "DAS query has invalid value, e.g. wrong dataset or run pattern"},
+}
+
+// helper function to return name of DAS exit code
+func dasExitCodeName(ecode int) string {
+	if ecode == 0 {
+		return "ok"
+	}
+	for _, e := range dasExitCodes {
+		if e.Code == ecode {
+			return e.Name
+		}
+	}
+	return "unknown"
+}
+
+func showDASExitCodes(jsonout bool) {
+	if jsonout {
+		printJSON(dasExitCodes)
+		return
+	}
 	fmt.Println("DAS exit codes:")
-	fmt.Printf("%v %s\n", utils.DASServerError, utils.DASServerErrorName)
-	fmt.Printf("%v %s\n", utils.DBSError, utils.DBSErrorName)
-	fmt.Printf("%v %s\n", utils.PhedexError, utils.PhedexErrorName)
-	fmt.Printf("%v %s\n", utils.RucioError, utils.RucioErrorName)
-	fmt.Printf("%v %s\n", utils.DynamoError, utils.DynamoErrorName)
-	fmt.Printf("%v %s\n", utils.ReqMgrError, utils.ReqMgrErrorName)
-	fmt.Printf("%v %s\n", utils.RunRegistryError, utils.RunRegistryErrorName)
-	fmt.Printf("%v %s\n", utils.McMError, utils.McMErrorName)
-	fmt.Printf("%v %s\n", utils.DashboardError, utils.DashboardErrorName)
-	fmt.Printf("%v %s\n", utils.SiteDBError, utils.SiteDBErrorName)
-	fmt.Printf("%v %s\n", utils.CRICError, utils.CRICErrorName)
-	fmt.Printf("%v %s\n", utils.CondDBError, utils.CondDBErrorName)
-	fmt.Printf("%v %s\n", utils.CombinedError, utils.CombinedErrorName)
-	fmt.Printf("%v %s\n", utils.MongoDBError, utils.MongoDBErrorName)
-	fmt.Printf("%v %s\n", utils.DASProxyError, utils.DASProxyErrorName)
-	fmt.Printf("%v %s\n", utils.DASQueryError, utils.DASQueryErrorName)
-	fmt.Printf("%v %s\n", utils.DASParserError, utils.DASParserErrorName)
-	fmt.Printf("%v %s\n", utils.DASValidationError, utils.DASValidationErrorName)
+	for _, e := range dasExitCodes {
+		fmt.Printf("%v %s\n", e.Code, e.Name)
+	}
 }
 
 func info() string {
@@ -259,25 +486,6 @@ func checkX509() {
 	}
 }
 
-// helper function to show examples of DAS queries
-func showExamples() {
-	examples := []string{"block_queries.txt", "file_queries.txt", "lumi_queries.txt", "mcm_queries.txt", "run_queries.txt", "dataset_queries.txt", "jobsummary_queries.txt", "misc_queries.txt", "site_queries.txt"}
-	var home string
-	for _, item := range os.Environ() {
-		value := strings.Split(item, "=")
-		if value[0] == "HOME" {
-			home = value[1]
-			break
-		}
-	}
-	for _, fname := range examples {
-		arr := strings.Split(fname, "_")
-		msg := fmt.Sprintf("### %s queries:\n", arr[0])
-		fmt.Println(strings.ToTitle(msg))
-		fmt.Println(utils.LoadExamples(fname, home))
-	}
-}
-
 // global keymap for DAS keys and associate CMS data-service
 func DASKeyMap() map[string][]string {
 	keyMap := map[string][]string{
@@ -305,10 +513,8 @@ func dasServices(rec mongo.DASRecord) []string {
 	return out
 }
 
-// helper function to show supported DAS keys
-func showDASKeys() {
-	var dmaps dasmaps.DASMaps
-	dmaps.LoadMapsFromFile()
+// helper function to get supported DAS keys and their CMS data-services
+func dasKeyServices(dmaps *dasmaps.DASMaps) map[string][]string {
 	keys := make(map[string][]string)
 	for _, rec := range dmaps.Maps() {
 		if rec["lookup"] != nil {
@@ -326,6 +532,18 @@ func showDASKeys() {
 			}
 		}
 	}
+	return keys
+}
+
+// helper function to show supported DAS keys
+func showDASKeys(jsonout bool) {
+	var dmaps dasmaps.DASMaps
+	dmaps.LoadMapsFromFile()
+	if jsonout {
+		printJSON(dasKeysInfo(&dmaps))
+		return
+	}
+	keys := dasKeyServices(&dmaps)
 	keyMap := DASKeyMap()
 	fmt.Println("DAS keys and associated CMS data-service info")
 	fmt.Println("---------------------------------------------")
@@ -370,31 +588,109 @@ func skipSystem(dasquery dasql.DASQuery, system string) bool {
 	return false
 }
 
-// Process function process' given query and return back results
-func process(
-	query string,
-	jsonout bool,
-	sep string,
-	unique bool,
-	format, host string,
-	rdx, limit int,
-	aggregate bool,
-	noDbsAgg bool) {
+// Options represents DAS query processing and output options
+type Options struct {
+	JSON      bool      // return results in JSON data-format
+	Sep       string    // separator of output values
+	Unique    bool      // sort results and return unique list
+	Format    string    // output format: json (das_client style), ndjson, csv, table or parquet
+	Profile   Profile   // environment of CMS data-services
+	Idx       int       // index of first record to output
+	Limit     int       // maximum number of records to output
+	Aggregate bool      // aggregate results across all data-services
+	NoDbsAgg  bool      // do not perform DBS aggregation
+	Explain   bool      // show CMS data-services and urls instead of DAS records
+	Arrays    string    // representation of DAS record lists: rows or join
+	Sort      []SortKey // sort keys of DAS records, order of primary key by default
+	Template  string    // Go template to format DAS records
+	Output    string    // name of output file, stdout is used by default
+}
 
-	// defer function profiler
-	defer utils.MeasureTime("dasgoclient/process")
+// helper function to check if options require JSON output of DAS records
+func (o Options) jsonOutput() bool {
+	format := strings.ToLower(o.Format)
+	return o.JSON || format == "json" || format == "ndjson"
+}
+
+// Result represents outcome of DAS query processing
+type Result struct {
+	Query      dasql.DASQuery    // parsed DAS query
+	Records    []mongo.DASRecord // DAS records
+	SelectKeys [][]string        // select keys of DAS records
+	Pipeline   Pipeline          // filters and aggregators of DAS query
+	Unique     bool              // return sorted unique list of records
+	Sorted     bool              // records are ordered by sort keys or aggregators
+	ECode      int               // DAS exit code
+	Error      string            // error message of DAS records
+	Message    string            // error message of failed DAS query
+	Fatal      bool              // DAS query failed before we obtained DAS records
+	Warnings   []string          // warnings to show to the user
+	Plan       []string          // explanation of DAS query processing
+	Start      time.Time         // start time of DAS query processing
+	span       *Span             // root trace span of DAS query
+}
 
-	time0 := time.Now().Unix()
-	if strings.ToLower(format) == "json" {
-		jsonout = true
+// helper function to adjust DAS query with filters and aggregators
+func prepareQuery(query string) string {
+	if strings.Contains(query, "|") && !strings.Contains(query, "detail") {
+		// for filters and aggregators we need to use detail=true flag
+		query = strings.Replace(query, "|", " detail=true |", 1)
 	}
+	return query
+}
+
+// helper function to load DAS maps and adjust them for given environment
+func loadDASMaps(envProfile Profile) *dasmaps.DASMaps {
 	var dmaps dasmaps.DASMaps
 	dmaps.LoadMapsFromFile()
-	if !strings.Contains(host, "cmsweb.cern.ch") {
-		dmaps.ChangeUrl("https://cmsweb.cern.ch", host)
-		dmaps.ChangeUrl("prod/global", "int/global")
+	if utils.VERBOSE > 0 {
+		if info, err := mapsInfo(localMapsFile()); err == nil {
+			fmt.Printf("DAS maps version %s, modified %s, stale %v\n", info.Version, info.Modified.Format(time.RFC3339), info.Stale)
+		}
 	}
-	dasquery, err, posLine := dasql.Parse(query, "", dmaps.DASKeys())
+	envProfile.AdjustMaps(&dmaps)
+	return &dmaps
+}
+
+// Process function process' given query and return back results
+func process(query string, opts Options) {
+
+	// defer function profiler
+	defer utils.MeasureTime("dasgoclient/process")
+
+	res := runQuery(context.Background(), query, opts, loadDASMaps(opts.Profile))
+	var ecode int
+	if opts.Output != "" {
+		ecode = writeOutput(res, opts)
+	} else {
+		ecode = writeResult(os.Stdout, res, opts)
+	}
+	dasMetrics.Query(strings.Join(res.Query.Fields, ","), ecode, time.Since(res.Start))
+	res.span.SetAttr("das.exit_code", ecode)
+	exit(ecode)
+}
+
+// helper function to export trace spans and exit with given DAS exit code
+func exit(ecode int) {
+	tracer.Flush()
+	os.Exit(ecode)
+}
+
+// helper function to run DAS query and obtain its DAS records, upstream
+// calls of DAS query are cancelled along with given context
+func runQuery(ctx context.Context, query string, opts Options, dmaps *dasmaps.DASMaps) Result {
+	jsonout := opts.jsonOutput()
+	unique := opts.Unique
+	aggregate := opts.Aggregate
+	res := Result{Start: time.Now()}
+	root := tracer.Start("dasgoclient", nil)
+	root.SetAttr("das.query", query)
+	res.span = root
+	span := tracer.Start("parse", root)
+	// DBS instance of the environment is used unless DAS query provides one
+	dasquery, pipeline, err, posLine := parseDASQuery(query, opts.Profile.Instance, dmaps.DASKeys())
+	res.Query = dasquery
+	res.Pipeline = pipeline
 	// special case for "file dataset" query
 	// if we have not given json output and there is no DAS filters we can safely
 	// use details=false in DBS queries
@@ -407,13 +703,20 @@ func process(
 		fmt.Println(posLine)
 	}
 	if err != "" {
-		fmt.Println("ERROR: das parser error:", err)
-		os.Exit(utils.DASParserError)
+		span.SetError(errors.New(err))
+		res.Message = fmt.Sprintf("ERROR: das parser error: %s", err)
+		res.ECode = utils.DASParserError
+		res.Fatal = true
+		return res
 	}
 	if e := dasql.ValidateDASQuerySpecs(dasquery); e != nil {
-		fmt.Println(e)
-		os.Exit(utils.DASValidationError)
+		span.SetError(e)
+		res.Message = e.Error()
+		res.ECode = utils.DASValidationError
+		res.Fatal = true
+		return res
 	}
+	span.End()
 
 	// check dasquery and overwrite unique filter for everything except file
 	if !unique && !utils.InList("file", dasquery.Fields) && !dasquery.Detail {
@@ -422,8 +725,10 @@ func process(
 	if utils.VERBOSE > 0 {
 		fmt.Println("### unique", unique)
 	}
+	res.Unique = unique
 
 	// find out list of APIs/CMS services which can process this query request
+	span = tracer.Start("select services", root)
 	maps := dmaps.FindServices(dasquery)
 	var mapServices []string
 	for _, dmap := range maps {
@@ -469,36 +774,42 @@ func process(
 
 	// return proper message if no selected services are found
 	if len(selectedServices) == 0 {
-		if jsonout {
-			fmt.Println("[")
-			msg := "no APIs found to answer your query"
-			rec := mongo.DASErrorRecord(msg, utils.DASQueryErrorName, utils.DASQueryError)
-			data, err := json.Marshal(rec)
-			if err == nil {
-				fmt.Println(string(data))
-			} else {
-				fmt.Printf("{\"status\":\"error\", \"error\":\"no APIs found to answer your query\"}")
-			}
-			fmt.Println("]")
-		}
-		os.Exit(utils.DASQueryError)
-
+		msg := "no APIs found to answer your query"
+		rec := mongo.DASErrorRecord(msg, utils.DASQueryErrorName, utils.DASQueryError)
+		res.Records = []mongo.DASRecord{rec}
+		res.ECode = utils.DASQueryError
+		res.Fatal = true
+		return res
 	}
 
 	// get list of services, pkeys, urls and localApis we need to process
 	srvs, pkeys, urls, localApis := das.ProcessLogic(dasquery, maps, selectedServices)
 
+	span.SetAttr("das.services", strings.Join(srvs, ","))
+	span.End()
 	if utils.VERBOSE > 0 {
 		fmt.Println("### selected services", srvs, pkeys)
 		fmt.Println("### selected urls", urls)
 		fmt.Println("### selected localApis", localApis)
 	}
+	if opts.Explain {
+		res.Plan = explainQuery(dasquery, opts.Profile, srvs, pkeys, urls, localApis)
+		return res
+	}
 	// extract selected keys from dasquery and primary keys
-	selectKeys, selectSubKeys := selectedKeys(dasquery, pkeys)
+	res.SelectKeys = selectedKeys(dasquery, pkeys)
+	if len(res.SelectKeys) == 0 {
+		res.Message = fmt.Sprintf("ERROR: Unable to parse DAS query, no select keys are found %v", dasquery)
+		res.ECode = utils.DASQueryError
+		res.Fatal = true
+		return res
+	}
 
+	stopFetch := queryStats.Measure("fetch")
+	span = tracer.Start("fetch", root)
 	var dasrecords []mongo.DASRecord
 	if len(urls) > 0 {
-		for _, r := range processURLs(dasquery, urls, maps, &dmaps, pkeys) {
+		for _, r := range processURLs(ctx, dasquery, urls, maps, dmaps, pkeys, span) {
 			dasrecords = append(dasrecords, r)
 		}
 		if utils.VERBOSE > 0 {
@@ -506,24 +817,37 @@ func process(
 		}
 	}
 	if len(localApis) > 0 {
-		for _, r := range processLocalApis(dasquery, localApis, pkeys) {
+		for _, r := range processLocalApis(ctx, dasquery, localApis, pkeys, span) {
 			dasrecords = append(dasrecords, r)
 		}
 		if utils.VERBOSE > 0 {
 			fmt.Println("#### processLocalApis", len(dasrecords))
 		}
 	}
+	stopFetch()
+	if err := ctx.Err(); err != nil {
+		span.SetError(err)
+		span.End()
+		res.Message = fmt.Sprintf("ERROR: DAS query is cancelled: %v", err)
+		res.ECode = utils.DASServerError
+		res.Fatal = true
+		return res
+	}
 	if utils.VERBOSE > 0 {
 		fmt.Println("Received", len(dasrecords), "records")
+		for _, msg := range rateLimiter.Summary() {
+			fmt.Println("### rate limiter", msg)
+		}
 	}
 
 	// perform post-processing of DAS records
 	//     dasrecords = das.PostProcessing(dasquery, dasrecords)
 
 	// check if site query returns nothing and then look-up data in DBS3
-	if len(dasrecords) == 0 && utils.InList("site", dasquery.Fields) {
+	// unless DAS query explicitly asks for specific CMS data-service
+	if len(dasrecords) == 0 && utils.InList("site", dasquery.Fields) && dasquery.System == "" {
 		if !jsonout {
-			fmt.Println("WARNING: No site records found in Rucio, will look-up original sites in DBS")
+			res.Warnings = append(res.Warnings, "WARNING: No site records found in Rucio, will look-up original sites in DBS")
 		}
 		if utils.VERBOSE > 0 {
 			fmt.Println("### site query returns nothing, will look-up data in DBS")
@@ -544,31 +868,32 @@ func process(
 				urls[furl] = args
 			}
 		}
-		for _, r := range processURLs(dasquery, urls, maps, &dmaps, pkeys) {
+		for _, r := range processURLs(ctx, dasquery, urls, maps, dmaps, pkeys, span) {
 			dasrecords = append(dasrecords, r)
 		}
 	}
+	span.SetAttr("das.records", len(dasrecords))
+	span.End()
+
+	res.ECode, res.Error = checkDASrecords(dasrecords)
+
+	// order DAS records by their primary keys regardless of order of
+	// responses of CMS data-services
+	dasrecords = sortByPrimaryKey(dasrecords)
 
-	ecode, dasError := checkDASrecords(dasrecords)
+	// apply filters of DAS query pipe
+	dasrecords = pipeline.Apply(dasrecords)
 
 	// apply aggregation
-	aggrs := dasquery.Aggregators
-	var out []mongo.DASRecord
-	if len(aggrs) > 0 {
-		for _, agg := range aggrs {
-			fagg := agg[0]
-			fval := agg[1]
-			if len(dasrecords) > 0 {
-				rec := das.Aggregate(dasrecords, fagg, fval)
-				out = append(out, rec)
-			}
-		}
-		dasrecords = out
+	stopAggregation := queryStats.Measure("aggregation")
+	span = tracer.Start("aggregation", root)
+	if len(dasquery.Aggregators) > 0 {
+		dasrecords = aggregateRecords(dasrecords, dasquery.Aggregators, pipeline.GroupBy)
 	}
 
 	// aggregate or not DBS results (new DBS Go server return results in
 	// no aggregated form while DBS Python server provides results aggregation)
-	if !noDbsAgg {
+	if !opts.NoDbsAgg {
 		if utils.InList("file", dasquery.Fields) && utils.InList("lumi", dasquery.Fields) {
 			dasrecords = aggregateFileLumis(dasrecords)
 		}
@@ -576,201 +901,347 @@ func process(
 			dasrecords = aggregateRuns(dasrecords)
 		}
 	}
+	stopAggregation()
+	span.SetAttr("das.records", len(dasrecords))
+	span.End()
+
+	// apply sort keys of -sort option
+	dasrecords = sortRecords(dasrecords, opts.Sort)
+	res.Sorted = pipeline.Sorted() || len(opts.Sort) > 0 || len(dasquery.Aggregators) > 0
+	res.Query = dasquery
+	res.Records = dasrecords
+	return res
+}
 
-	// if user provides format option we'll add extra fields to be compatible with das_client
-	if strings.ToLower(format) == "json" {
-		ctime := time.Now().Unix() - time0
-		// add status wrapper to be compatible with das_client.py
-		fmt.Printf("{\"status\":\"ok\", \"ecode\":\"%s\", \"mongo_query\":%s, \"nresults\":%d, \"timestamp\":%d, \"ctime\":%d, \"data\":", dasError, dasquery.Marshall(), len(dasrecords), time.Now().Unix(), ctime)
+// helper function to explain which CMS data-services, urls and local APIs
+// will be used to answer DAS query
+func explainQuery(dasquery dasql.DASQuery, envProfile Profile, srvs, pkeys []string, urls map[string]string, localApis []mongo.DASRecord) []string {
+	var out []string
+	out = append(out, fmt.Sprintf("query: %s", dasquery.Marshall()))
+	out = append(out, fmt.Sprintf("environment: %s", envProfile))
+	out = append(out, fmt.Sprintf("services: %s", strings.Join(srvs, ",")))
+	out = append(out, fmt.Sprintf("primary keys: %s", strings.Join(utils.List2Set(pkeys), ",")))
+	var furls []string
+	for furl, args := range urls {
+		if args != "" {
+			furl = fmt.Sprintf("%s %s", furl, args)
+		}
+		furls = append(furls, furl)
+	}
+	sort.Strings(furls)
+	for _, furl := range furls {
+		out = append(out, fmt.Sprintf("url: %s", furl))
 	}
+	for _, dmap := range localApis {
+		api := fmt.Sprintf("%s_%s", dasmaps.GetString(dmap, "system"), dasmaps.GetString(dmap, "urn"))
+		out = append(out, fmt.Sprintf("local api: %s", api))
+	}
+	return out
+}
 
-	// if we use detail=True option in json format we'll dump entire dasrecords
-	if dasquery.Detail && jsonout {
-		fmt.Println("[") // data output goes here
-		for idx, rec := range dasrecords {
-			if idx < rdx {
-				continue
-			}
-			out, err := json.Marshal(rec)
-			if err == nil {
-				if idx < len(dasrecords)-1 {
-					if limit > 0 && limit == idx+1 {
-						fmt.Println(string(out))
-						break
-					}
-					fmt.Println(string(out), ",")
+// helper function to write results of DAS query to given writer, it returns
+// DAS exit code
+func writeResult(w io.Writer, res Result, opts Options) int {
+	ecode, _, _ := writeRecords(w, res, opts)
+	return ecode
+}
+
+// helper function to write results of DAS query to given writer, it returns
+// DAS exit code, number of written records and error which makes written
+// output unusable, e.g. failed template or data-format encoding
+func writeRecords(w io.Writer, res Result, opts Options) (int, int, error) {
+	format := strings.ToLower(opts.Format)
+	jsonout := opts.jsonOutput()
+	rdx := opts.Idx
+	limit := opts.Limit
+	dasquery := res.Query
+	dasrecords := res.Records
+	ecode := res.ECode
+	dasError := res.Error
+
+	// DAS query failed before we got any results
+	if res.Fatal && format == "json" {
+		ecode, err := writeDASClient(w, res, nil)
+		return ecode, 0, err
+	}
+	if res.Fatal {
+		if res.Message != "" {
+			fmt.Fprintln(w, res.Message)
+		}
+		if jsonout && len(dasrecords) > 0 {
+			fmt.Fprintln(w, "[")
+			for _, rec := range dasrecords {
+				data, err := json.Marshal(rec)
+				if err == nil {
+					fmt.Fprintln(w, string(data))
 				} else {
-					fmt.Println(string(out))
+					fmt.Fprintf(w, "{\"status\":\"error\", \"error\":\"%s\"}", utils.DASQueryErrorName)
 				}
-			} else {
-				fmt.Println("ERROR: DAS record", rec, "fail to marshal it to JSON stream")
-				os.Exit(utils.DASServerError)
 			}
+			fmt.Fprintln(w, "]")
+		}
+		return ecode, 0, nil
+	}
+	// messages of binary data-formats and output files are written to stderr
+	// to keep their data intact, DAS exit code of output file is kept in its
+	// metadata file
+	msgw := w
+	if format == "parquet" || opts.Output != "" {
+		msgw = os.Stderr
+	}
+	for _, msg := range res.Warnings {
+		fmt.Fprintln(msgw, msg)
+	}
+	if len(res.Plan) > 0 {
+		for _, msg := range res.Plan {
+			fmt.Fprintln(w, msg)
+		}
+		return ecode, 0, nil
+	}
+
+	stopFormat := queryStats.Measure("format")
+	span := tracer.Start("output", res.span)
+
+	// template output is produced from DAS records after their aggregation
+	if opts.Template != "" {
+		nrec, err := writeTemplate(w, res, opts)
+		stopFormat()
+		span.SetAttr("das.records", nrec)
+		span.End()
+		if err != nil {
+			fmt.Fprintln(msgw, "ERROR: unable to execute template:", err)
+			return utils.DASQueryError, 0, err
+		}
+		writeStats(w, nrec, false)
+		if ecode != 0 {
+			fmt.Fprintln(msgw, "ERROR: das exit with code:", ecode, ", error:", dasError)
+		}
+		return ecode, nrec, nil
+	}
+
+	// grep filter projects DAS records into selected attributes
+	project := len(dasquery.Filters["grep"]) > 0 && len(dasquery.Aggregators) == 0
+
+	// if we use detail=True option in json format we'll dump entire dasrecords
+	if dasquery.Detail && jsonout && format != "ndjson" && !project {
+		var records []string
+		for _, rec := range dasrecords {
+			out, err := json.Marshal(rec)
+			if err != nil {
+				fmt.Fprintln(msgw, "ERROR: DAS record", rec, "fail to marshal it to JSON stream")
+				return utils.DASServerError, 0, err
+			}
+			records = append(records, string(out))
+		}
+		records = selectRecords(records, rdx, limit)
+		stopFormat()
+		span.SetAttr("das.records", len(dasrecords))
+		span.End()
+		// das_client style output wraps DAS records
+		if format == "json" {
+			ecode, err := writeDASClient(w, res, records)
+			return ecode, len(records), err
+		}
+		writeJSONRecords(w, records)
+		writeStats(w, len(dasrecords), false)
+		if ecode != 0 {
+			fmt.Fprintln(msgw, "ERROR: das exit with code:", ecode, ", error:", dasError)
+		}
+		return ecode, len(records), nil
+	}
+
+	// csv output is produced from values of DAS records attributes
+	if format == "csv" {
+		nrec := writeCSV(w, res, opts)
+		stopFormat()
+		span.SetAttr("das.records", nrec)
+		span.End()
+		writeStats(w, nrec, false)
+		return ecode, nrec, nil
+	}
+
+	// parquet output contains typed columns of DAS records attributes
+	if format == "parquet" {
+		nrec, err := writeParquet(w, res, opts)
+		stopFormat()
+		span.SetAttr("das.records", nrec)
+		span.End()
+		if err != nil {
+			fmt.Fprintln(msgw, "ERROR: unable to write parquet data:", err)
+			return utils.DASServerError, 0, err
 		}
-		fmt.Println("]") // end of data output
-		if strings.ToLower(format) == "json" {
-			fmt.Println("}") // end of status wrapper
+		writeStats(w, nrec, false)
+		if ecode != 0 {
+			fmt.Fprintln(msgw, "ERROR: das exit with code:", ecode, ", error:", dasError)
 		}
+		return ecode, nrec, nil
+	}
+
+	// table output is aligned to the width of terminal
+	if format == "table" {
+		nrec := writeTable(w, res, opts, terminalWidth(w))
+		stopFormat()
+		span.SetAttr("das.records", nrec)
+		span.End()
+		writeStats(w, nrec, false)
 		if ecode != 0 {
-			fmt.Println("ERROR: das exit with code:", ecode, ", error:", dasError)
+			fmt.Fprintln(msgw, "ERROR: das exit with code:", ecode, ", error:", dasError)
 		}
-		os.Exit(ecode)
+		return ecode, nrec, nil
 	}
 
 	// for non-detailed output, we first get records, then convert them to a set and sort them
+	sep := opts.Sep
 	var records []string
-	if len(dasquery.Filters) > 0 {
-		if strings.ToLower(format) == "json" {
-			// TODO: to simplify so far I'll ignore filters since records should be returned as dict
-			// but later I need to adjust code to return only filter's attribute
-			records = getRecords(dasrecords, selectKeys, selectSubKeys, sep, jsonout)
+	if len(dasquery.Aggregators) > 0 {
+		if format == "json" || format == "ndjson" {
+			records = getRecords(dasrecords, res.SelectKeys, sep, opts.Arrays, jsonout)
 		} else {
-			records = getFilteredRecords(dasquery, dasrecords, sep)
+			records = getAggregatedRecords(dasquery, dasrecords, res.Pipeline.GroupBy, sep)
 		}
-	} else if len(dasquery.Aggregators) > 0 {
-		if strings.ToLower(format) == "json" {
-			records = getRecords(dasrecords, selectKeys, selectSubKeys, sep, jsonout)
+	} else if project {
+		if jsonout {
+			records = getProjectedRecords(dasquery, dasrecords)
 		} else {
-			records = getAggregatedRecords(dasquery, dasrecords, sep)
+			records = getFilteredRecords(dasquery, dasrecords, sep, opts.Arrays)
 		}
 	} else {
-		records = getRecords(dasrecords, selectKeys, selectSubKeys, sep, jsonout)
+		records = getRecords(dasrecords, res.SelectKeys, sep, opts.Arrays, jsonout)
 	}
-	if unique {
+	if res.Unique {
 		records = utils.List2Set(records)
-		sort.Sort(utils.StringList(records))
+		// keep order of records sorted by sort keys or aggregators, otherwise
+		// sort them by values of their columns
+		if !res.Sorted && !jsonout {
+			sort.SliceStable(records, func(i, j int) bool {
+				return compareLines(records[i], records[j], sep) < 0
+			})
+		}
+	}
+	// ndjson output contains one JSON record per line
+	if format == "ndjson" {
+		jsonout = false
 	}
 	if jsonout {
-		fmt.Println("[")
+		records = selectRecords(records, rdx, limit)
+		stopFormat()
+		span.SetAttr("das.records", len(records))
+		span.End()
+		// das_client style output wraps DAS records
+		if format == "json" {
+			ecode, err := writeDASClient(w, res, records)
+			return ecode, len(records), err
+		}
+		writeJSONRecords(w, records)
+		writeStats(w, len(records), false)
+		if ecode != 0 {
+			fmt.Fprintln(msgw, "ERROR: das exit with code:", ecode, ", error:", dasError)
+		}
+		return ecode, len(records), nil
 	}
+	var nrec int
 	for idx, rec := range records {
 		if idx < rdx {
 			continue
 		}
-		if jsonout {
-			if idx < len(records)-1 {
-				if limit > 0 && limit == idx+1 {
-					fmt.Println(rec)
-					break
-				}
-				fmt.Println(rec, ",")
-			} else {
-				fmt.Println(rec)
-			}
-		} else {
-			if limit > 0 && limit == idx {
-				break
-			}
-			fmt.Println(rec)
+		if limit > 0 && limit == idx {
+			break
 		}
+		fmt.Fprintln(w, rec)
+		nrec++
 	}
-	if jsonout {
-		fmt.Println("]")
-	}
+	stopFormat()
+	span.SetAttr("das.records", len(records))
+	span.End()
+	writeStats(w, len(records), false)
 
-	if strings.ToLower(format) == "json" {
-		fmt.Printf("}")
-	}
 	if ecode != 0 {
-		fmt.Println("ERROR: das exit with code:", ecode, ", error:", dasError)
+		fmt.Fprintln(msgw, "ERROR: das exit with code:", ecode, ", error:", dasError)
 	}
-	os.Exit(ecode)
+	return ecode, nrec, nil
 }
 
-// helper function to extract filtered fields from DAS records
-func getFilteredRecords(dasquery dasql.DASQuery, dasrecords []mongo.DASRecord, sep string) []string {
+// helper function to extract filtered fields from DAS records, elements of
+// DAS records lists are shown according to arrays mode
+func getFilteredRecords(dasquery dasql.DASQuery, dasrecords []mongo.DASRecord, sep, arrays string) []string {
 
 	// defer function profiler
 	defer utils.MeasureTime("dasgoclient/getFilteredRecords")
 
 	var records []string
 	if dasfilters, ok := dasquery.Filters["grep"]; ok {
-		var filterEntries [][]string
-		for _, filter := range dasfilters {
-			var entries []string
-			for idx, val := range strings.Split(filter, ".") {
-				entries = append(entries, val)
-				if idx == 0 {
-					entries = append(entries, "[0]")
-				}
-			}
-			filterEntries = append(filterEntries, entries)
-		}
 		for _, rec := range dasrecords {
-			var out []string
-			for _, filters := range filterEntries {
-				rbytes, err := mongo.GetBytesFromDASRecord(rec)
-				if err != nil {
-					fmt.Printf("Fail to parse DAS record=%+v, error=%v\n", rec, err)
-				} else {
-					val, _, _, err := jsonparser.Get(rbytes, filters...)
-					if err != nil {
-						if utils.VERBOSE > 0 {
-							fmt.Printf("Unable to extract filters=%v, error=%v\n", filters, err)
-						}
-					} else {
-						out = append(out, string(val))
-					}
-				}
-				out = append(out, sep)
+			if recordError(rec) != nil {
+				continue
 			}
-			if len(out) > 0 {
-				records = append(records, strings.Join(out, sep))
+			for _, row := range projectRows(genericRecord(rec), dasfilters, arrays) {
+				records = append(records, strings.Join(row, sep))
 			}
 		}
 	}
 	return records
 }
 
-// helper function to extract aggregated fields from DAS records
-func getAggregatedRecords(dasquery dasql.DASQuery, dasrecords []mongo.DASRecord, sep string) []string {
+// helper function to project DAS records into JSON objects with filtered
+// fields only
+func getProjectedRecords(dasquery dasql.DASQuery, dasrecords []mongo.DASRecord) []string {
+
+	// defer function profiler
+	defer utils.MeasureTime("dasgoclient/getProjectedRecords")
+
+	var records []string
+	for _, rec := range dasrecords {
+		if recordError(rec) != nil {
+			continue
+		}
+		data, err := json.Marshal(projectRecord(genericRecord(rec), dasquery.Filters["grep"]))
+		if err != nil {
+			fmt.Println("ERROR: DAS record", rec, "fail to marshal it to JSON stream")
+			continue
+		}
+		records = append(records, string(data))
+	}
+	return records
+}
+
+// helper function to extract aggregated fields from DAS records, results
+// of the same group are shown on a single line
+func getAggregatedRecords(dasquery dasql.DASQuery, dasrecords []mongo.DASRecord, groupBy []string, sep string) []string {
 
 	// defer function profiler
 	defer utils.MeasureTime("dasgoclient/getAggregatedRecords")
 
 	var records []string
+	var group string
+	var out []string
 	for _, rec := range dasrecords {
-		var out []string
-		for _, agg := range dasquery.Aggregators {
-			fagg := agg[0]
-			if fagg != rec["function"] {
-				continue
+		fagg, _ := rec["function"].(string)
+		fval, _ := rec["key"].(string)
+		if fagg == "" {
+			continue
+		}
+		if g := strings.Join(aggregatedGroup(rec, groupBy), sep); len(groupBy) == 0 || g != group || len(out) == 0 {
+			if len(out) > 0 {
+				records = append(records, strings.Join(out, sep))
 			}
-			fval := agg[1]
-			var sval string
-			switch res := rec["result"].(type) {
-			case map[string]interface{}:
-				switch val := res["value"].(type) {
-				case int64:
-					sval = fmt.Sprintf("%s(%s): %d", fagg, fval, val)
-				case float64:
-					sval = fmt.Sprintf("%s(%s): %d", fagg, fval, int64(val))
-				default:
-					sval = fmt.Sprintf("%s(%s): %v", fagg, fval, val)
-				}
-			case mongo.DASRecord:
-				switch val := res["value"].(type) {
-				case int64:
-					sval = fmt.Sprintf("%s(%s): %d", fagg, fval, val)
-				case float64:
-					sval = fmt.Sprintf("%s(%s): %d", fagg, fval, int64(val))
-				default:
-					sval = fmt.Sprintf("%s(%s): %v", fagg, fval, val)
-				}
+			group = g
+			out = nil
+			if g != "" {
+				out = append(out, g)
 			}
-			out = append(out, sval)
-		}
-		if len(out) > 0 {
-			records = append(records, strings.Join(out, sep))
 		}
+		out = append(out, fmt.Sprintf("%s(%s): %s", fagg, fval, formatAggregate(aggregatedValue(rec))))
+	}
+	if len(out) > 0 {
+		records = append(records, strings.Join(out, sep))
 	}
 	return records
 }
 
 // helper function to extract selected keys of DAS queryes from primary keys
-func selectedKeys(dasquery dasql.DASQuery, pkeys []string) ([][]string, [][]string) {
+func selectedKeys(dasquery dasql.DASQuery, pkeys []string) [][]string {
 	// extract list of select keys we'll need to display on stdout
-	var selectKeys, selectSubKeys [][]string
+	var selectKeys [][]string
 	for _, pkey := range pkeys {
 		var skeys []string
 		for _, kkk := range strings.Split(pkey, ".") {
@@ -779,30 +1250,21 @@ func selectedKeys(dasquery dasql.DASQuery, pkeys []string) ([][]string, [][]stri
 			}
 		}
 		selectKeys = append(selectKeys, skeys) // hold [ key attribute ]
-		var keys []string
-		for _, kkk := range strings.Split(pkey, ".") {
-			if !utils.InList(kkk, keys) {
-				keys = append(keys, kkk)
-				if len(keys) == 1 {
-					keys = append(keys, "[0]") // to hadle DAS records lists
-				}
-			}
-		}
-		selectSubKeys = append(selectSubKeys, keys) // hold  [key [0] attribute]
 	}
-	if len(selectKeys) == 0 {
-		fmt.Println("ERROR: Unable to parse DAS query, no select keys are found", dasquery)
-		os.Exit(utils.DASQueryError)
-	}
-	return selectKeys, selectSubKeys
+	return selectKeys
 }
 
 // helper function to print DAS records on stdout
-func getRecords(dasrecords []mongo.DASRecord, selectKeys, selectSubKeys [][]string, sep string, jsonout bool) []string {
+func getRecords(dasrecords []mongo.DASRecord, selectKeys [][]string, sep, arrays string, jsonout bool) []string {
 
 	// defer function profiler
 	defer utils.MeasureTime("dasgoclient/getRecords")
 
+	var attrs []string
+	for _, keys := range selectKeys {
+		attrs = append(attrs, strings.Join(keys, "."))
+	}
+	attrs = utils.List2Set(attrs)
 	var records []string
 	for _, rec := range dasrecords {
 		das := rec["das"].(mongo.DASRecord)
@@ -838,57 +1300,28 @@ func getRecords(dasrecords []mongo.DASRecord, selectKeys, selectSubKeys [][]stri
 		if !jsonout && skip {
 			continue
 		}
-		rbytes, err := mongo.GetBytesFromDASRecord(rec)
-		if err != nil {
-			fmt.Printf("Fail to parse DAS record=%v, selKeys=%+v, error=%v\n", rec, selectKeys, err)
-		} else {
-			if jsonout {
-				out, err := json.Marshal(rec)
-				if err != nil {
-					fmt.Printf("Fail to marshal DAS record=%v, error=%v\n", rec, err)
-				}
-				records = append(records, string(out))
-				continue
-			}
-			var out []string
-			for _, keys := range selectKeys {
-				val, _, _, err := jsonparser.Get(rbytes, keys...)
-				if err == nil {
-					sval := string(val)
-					if !utils.InList(sval, out) {
-						out = append(out, sval)
-					}
-				} else {
-					if utils.VERBOSE > 1 {
-						fmt.Printf("jsonparser failure of DAS record=%+v, select keys=%v, error=%v\n", string(rbytes), keys, err)
-					}
-				}
-			}
-			if len(out) > 0 {
-				records = append(records, strings.Join(out, sep))
-			} else { // try out [key [0] attribute]
-				for _, keys := range selectSubKeys {
-					val, _, _, err := jsonparser.Get(rbytes, keys...)
-					if err == nil {
-						sval := string(val)
-						if !utils.InList(sval, out) {
-							out = append(out, sval)
-						}
-					} else {
-						fmt.Printf("jsonparser failure of DAS record=%+v, select sub keys=%v, error=%v\n", string(rbytes), keys, err)
-					}
-				}
-				if len(out) > 0 {
-					records = append(records, strings.Join(out, sep))
-				}
+		if jsonout {
+			out, err := json.Marshal(rec)
+			if err != nil {
+				fmt.Printf("Fail to marshal DAS record=%v, error=%v\n", rec, err)
 			}
+			records = append(records, string(out))
+			continue
+		}
+		rows := projectRows(genericRecord(rec), attrs, arrays)
+		if missing := missingAttributes(rows, attrs); len(missing) > 0 && utils.VERBOSE > 0 {
+			fmt.Printf("DAS record=%v misses attributes %v\n", rec, missing)
+		}
+		for _, row := range rows {
+			records = append(records, strings.Join(row, sep))
 		}
 	}
 	return records
 }
 
 // helper function to get DAS records out of url response
-func response2Records(r *utils.ResponseType, dasquery dasql.DASQuery, maps []mongo.DASRecord, dmaps *dasmaps.DASMaps, pkeys []string) []mongo.DASRecord {
+// the span of url fetch is ended once records are processed
+func response2Records(r *utils.ResponseType, dasquery dasql.DASQuery, maps []mongo.DASRecord, dmaps *dasmaps.DASMaps, pkeys []string, span *Span) []mongo.DASRecord {
 
 	// defer function profiler
 	defer utils.MeasureTime("dasgoclient/response2Records")
@@ -920,6 +1353,12 @@ func response2Records(r *utils.ResponseType, dasquery dasql.DASQuery, maps []mon
 	notations := dmaps.FindNotations(system)
 	records := services.Unmarshal(dasquery, system, urn, *r, notations, pkeys)
 	records = services.AdjustRecords(dasquery, system, urn, records, expire, pkeys)
+	queryStats.AddResponse(r, system, len(records))
+	span.SetAttr("das.service", system)
+	span.SetAttr("das.urn", urn)
+	span.SetAttr("das.records", len(records))
+	span.SetError(r.Error)
+	span.End()
 
 	// add records
 	for _, rec := range records {
@@ -928,8 +1367,16 @@ func response2Records(r *utils.ResponseType, dasquery dasql.DASQuery, maps []mon
 	return dasrecords
 }
 
-// helper function to process given set of URLs associted with dasquery
-func processURLs(dasquery dasql.DASQuery, urls map[string]string, maps []mongo.DASRecord, dmaps *dasmaps.DASMaps, pkeys []string) []mongo.DASRecord {
+// urlResponse represents response of url fetch along with its span
+type urlResponse struct {
+	resp utils.ResponseType
+	span *Span
+}
+
+// helper function to process given set of URLs associted with dasquery,
+// fetch spans of urls become children of given parent span and urls are
+// not fetched anymore once given context is done
+func processURLs(ctx context.Context, dasquery dasql.DASQuery, urls map[string]string, maps []mongo.DASRecord, dmaps *dasmaps.DASMaps, pkeys []string, parent *Span) []mongo.DASRecord {
 
 	// defer function profiler
 	defer utils.MeasureTime("dasgoclient/processURLs")
@@ -938,7 +1385,7 @@ func processURLs(dasquery dasql.DASQuery, urls map[string]string, maps []mongo.D
 	//     defer utils.ErrPropagate("processUrls")
 
 	var dasrecords []mongo.DASRecord
-	client := utils.HttpClient()
+	client := httpClient()
 	if len(urls) == 1 {
 		for furl, args := range urls {
 			if dasquery.Detail && strings.Contains(furl, "detail=False") {
@@ -947,12 +1394,26 @@ func processURLs(dasquery dasql.DASQuery, urls map[string]string, maps []mongo.D
 			//             if !dasquery.Detail && strings.Contains(furl, "detail=True") {
 			//                 furl = strings.Replace(furl, "detail=True", "detail=False", -1)
 			//             }
-			resp := utils.FetchResponse(client, furl, args)
-			return response2Records(&resp, dasquery, maps, dmaps, pkeys)
+			span := tracer.StartURL(furl, urlService(furl), parent)
+			wait, err := rateLimiter.Wait(ctx, furl)
+			queryStats.AddWait(furl, wait)
+			span.SetAttr("das.wait_ms", msec(wait))
+			if utils.VERBOSE > 0 && wait > 0 {
+				fmt.Println("### rate limiter wait", wait, furl)
+			}
+			if err != nil {
+				span.SetError(err)
+				span.End()
+				return dasrecords
+			}
+			fctx := contextWithAttempts(contextWithSpan(ctx, span))
+			resp := utils.FetchResponse(contextClient(fctx, client), furl, args)
+			return response2Records(&resp, dasquery, maps, dmaps, pkeys, span)
 		}
 	}
-	out := make(chan utils.ResponseType)
-	defer close(out)
+	// the channel is buffered and never closed, i.e. fetch goroutines which
+	// complete after cancellation of DAS query do not block
+	out := make(chan urlResponse, len(urls))
 	umap := map[string]int{}
 	for furl, args := range urls {
 		if dasquery.Detail && strings.Contains(furl, "detail=False") {
@@ -962,7 +1423,26 @@ func processURLs(dasquery dasql.DASQuery, urls map[string]string, maps []mongo.D
 		//             furl = strings.Replace(furl, "detail=True", "detail=False", -1)
 		//         }
 		umap[furl] = 1 // keep track of processed urls below
-		go utils.Fetch(client, furl, args, out)
+		// apply rate limiter before we pass url to fetch queue
+		span := tracer.StartURL(furl, urlService(furl), parent)
+		go func(furl, args string) {
+			wait, err := rateLimiter.Wait(ctx, furl)
+			queryStats.AddWait(furl, wait)
+			span.SetAttr("das.wait_ms", msec(wait))
+			if utils.VERBOSE > 0 && wait > 0 {
+				fmt.Println("### rate limiter wait", wait, furl)
+			}
+			if err != nil {
+				out <- urlResponse{utils.ResponseType{Url: furl, Error: err}, span}
+				return
+			}
+			// every fetch carries its own span and HTTP calls counter
+			// in request context
+			ch := make(chan utils.ResponseType, 1)
+			fctx := contextWithAttempts(contextWithSpan(ctx, span))
+			utils.Fetch(contextClient(fctx, client), furl, args, ch)
+			out <- urlResponse{<-ch, span}
+		}(furl, args)
 	}
 
 	// collect all results from out channel
@@ -970,15 +1450,18 @@ func processURLs(dasquery dasql.DASQuery, urls map[string]string, maps []mongo.D
 	for {
 		select {
 		case r := <-out:
-			for _, rec := range response2Records(&r, dasquery, maps, dmaps, pkeys) {
+			for _, rec := range response2Records(&r.resp, dasquery, maps, dmaps, pkeys, r.span) {
 				dasrecords = append(dasrecords, rec)
 			}
 			// remove from umap, indicate that we processed it
-			delete(umap, r.Url) // remove Url from map
+			delete(umap, r.resp.Url) // remove Url from map
 		default:
 			if len(umap) == 0 { // no more requests, merge data records
 				exit = true
 			}
+			if ctx.Err() != nil { // DAS query is cancelled
+				exit = true
+			}
 			time.Sleep(time.Duration(10) * time.Millisecond) // wait for response
 		}
 		if exit {
@@ -988,8 +1471,10 @@ func processURLs(dasquery dasql.DASQuery, urls map[string]string, maps []mongo.D
 	return dasrecords
 }
 
-// helper function to process given set of URLs associted with dasquery
-func processLocalApis(dasquery dasql.DASQuery, dmaps []mongo.DASRecord, pkeys []string) []mongo.DASRecord {
+// helper function to process given set of URLs associted with dasquery,
+// local API spans become children of given parent span and local APIs are
+// not called anymore once given context is done
+func processLocalApis(ctx context.Context, dasquery dasql.DASQuery, dmaps []mongo.DASRecord, pkeys []string, parent *Span) []mongo.DASRecord {
 
 	// defer function profiler
 	defer utils.MeasureTime("dasgoclient/processLocalApis")
@@ -997,6 +1482,9 @@ func processLocalApis(dasquery dasql.DASQuery, dmaps []mongo.DASRecord, pkeys []
 	var dasrecords []mongo.DASRecord
 	localApiMap := services.LocalAPIMap()
 	for _, dmap := range dmaps {
+		if ctx.Err() != nil {
+			break
+		}
 		urn := dasmaps.GetString(dmap, "urn")
 		system := dasmaps.GetString(dmap, "system")
 		expire := dasmaps.GetInt(dmap, "expire")
@@ -1005,6 +1493,10 @@ func processLocalApis(dasquery dasql.DASQuery, dmaps []mongo.DASRecord, pkeys []
 		if utils.VERBOSE > 0 {
 			fmt.Println("DAS local API", api)
 		}
+		span := tracer.Start("local api", parent)
+		span.SetAttr("das.api", api)
+		span.SetAttr("das.service", system)
+		time0 := time.Now()
 		// we use reflection to look-up api from our services/localapis.go functions
 		// for details on reflection see
 		// http://stackoverflow.com/questions/12127585/go-lookup-function-by-name
@@ -1013,6 +1505,9 @@ func processLocalApis(dasquery dasql.DASQuery, dmaps []mongo.DASRecord, pkeys []
 		args := []reflect.Value{reflect.ValueOf(dasquery)} // list of function arguments
 		vals := m.Call(args)[0]                            // return value
 		records := vals.Interface().([]mongo.DASRecord)    // cast reflect value to its type
+		queryStats.AddLocalApi(api, system, time.Since(time0), len(records))
+		span.SetAttr("das.records", len(records))
+		span.End()
 		if utils.VERBOSE > 0 {
 			fmt.Println("### LOCAL APIS", urn, system, expire, dmap, api, m, len(records))
 		}
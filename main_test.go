@@ -44,7 +44,7 @@ func TestStatus(t *testing.T) {
 	examples := []string{"block_queries.txt", "file_queries.txt", "lumi_queries.txt", "mcm_queries.txt", "run_queries.txt", "dataset_queries.txt", "jobsummary_queries.txt", "misc_queries.txt", "site_queries.txt"}
 	dasKeys := []string{"expire", "instance", "primary_key", "record", "services"}
 	sort.Sort(utils.StringList(dasKeys))
-	recKeys := []string{"status", "mongo_query", "nresults", "timestamp", "ctime", "data"}
+	recKeys := []string{"status", "ecode", "mongo_query", "nresults", "timestamp", "ctime", "data"}
 	sort.Sort(utils.StringList(recKeys))
 	var rec mongo.DASRecord
 	var home string
@@ -0,0 +1,592 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/utils"
+)
+
+// default source of DAS maps, see dasmaps.LoadMapsFromFile
+var dasMapsSource = "https://raw.githubusercontent.com/dmwm/DASMaps/master/js/das_maps_dbs_prod.js"
+
+// DAS maps are downloaded again once they are older than this interval,
+// see dasmaps.LoadMapsFromFile
+var dasMapsTTL = 24 * time.Hour
+
+// list of maps sub-commands
+var mapsCommands = []string{"list", "validate", "diff", "fetch", "info", "check"}
+
+// MapRecord represents DAS map record along with its line in DAS maps file
+type MapRecord struct {
+	Line   int
+	Record map[string]interface{}
+}
+
+// MapEntry represents DAS map of CMS data-service API
+type MapEntry struct {
+	System    string   `json:"system"`
+	Urn       string   `json:"urn"`
+	Lookup    string   `json:"lookup"`
+	Url       string   `json:"url"`
+	Keys      []string `json:"das_keys"`
+	Instances []string `json:"instances,omitempty"`
+}
+
+// MapIssue represents problem found in DAS maps file
+type MapIssue struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// MapChange represents difference of DAS map record between two files
+type MapChange struct {
+	Id     string   `json:"id"`
+	Change string   `json:"change"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// MapsInfo represents information about local DAS maps
+type MapsInfo struct {
+	File     string    `json:"file"`
+	Version  string    `json:"version"`
+	Modified time.Time `json:"modified"`
+	Age      string    `json:"age"`
+	Stale    bool      `json:"stale"`
+	Records  int       `json:"records"`
+	Systems  []string  `json:"systems"`
+	Source   string    `json:"source,omitempty"`
+	Remote   string    `json:"remote_version,omitempty"`
+	Outdated *bool     `json:"outdated,omitempty"`
+}
+
+// helper function to get location of DAS maps file, it follows the same
+// convention as dasmaps.LoadMapsFromFile
+func localMapsFile() string {
+	if utils.DASMAPS != "" {
+		if stat, err := os.Stat(utils.DASMAPS); err == nil && !stat.IsDir() {
+			return utils.DASMAPS
+		}
+		return filepath.Join(utils.DASMAPS, ".dasmaps", "das_maps_dbs_prod.js")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".dasmaps", "das_maps_dbs_prod.js")
+}
+
+// helper function to load DAS maps from local file without network access
+func loadLocalDASMaps() (*dasmaps.DASMaps, error) {
+	fname := localMapsFile()
+	if _, err := os.Stat(fname); err != nil {
+		return nil, fmt.Errorf("no local DAS maps found, please use -dasmaps option: %v", err)
+	}
+	var dmaps dasmaps.DASMaps
+	dmaps.ReadMapFile(fname)
+	if len(dmaps.DASKeys()) == 0 {
+		return nil, fmt.Errorf("no DAS keys found in %s", fname)
+	}
+	return &dmaps, nil
+}
+
+// helper function to calculate version (checksum) of DAS maps
+func mapsVersion(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:12]
+}
+
+// helper function to parse DAS maps data, it returns DAS map records and
+// lines which can't be parsed
+func parseMaps(data []byte) ([]MapRecord, []MapIssue) {
+	var records []MapRecord
+	var issues []MapIssue
+	for idx, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			issues = append(issues, MapIssue{Line: idx + 1, Error: fmt.Sprintf("invalid JSON: %v", err)})
+			continue
+		}
+		records = append(records, MapRecord{Line: idx + 1, Record: rec})
+	}
+	return records, issues
+}
+
+// helper function to read DAS maps file
+func readMaps(fname string) ([]MapRecord, []MapIssue, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, issues := parseMaps(data)
+	return records, issues, nil
+}
+
+// helper function to get string value of DAS map record
+func mapString(rec map[string]interface{}, key string) string {
+	if v, ok := rec[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// helper function to get list of strings of DAS map record
+func mapStrings(rec map[string]interface{}, key string) []string {
+	var out []string
+	if vals, ok := rec[key].([]interface{}); ok {
+		for _, v := range vals {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// helper function to get DAS keys of DAS map record
+func mapKeys(rec map[string]interface{}) []string {
+	var out []string
+	if items, ok := rec["das_map"].([]interface{}); ok {
+		for _, item := range items {
+			if m, ok := mapRecord(item); ok {
+				if key := mapString(m, "das_key"); key != "" && !utils.InList(key, out) {
+					out = append(out, key)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// helper function to get identifier of DAS map record, it is used to
+// match records of different DAS maps files
+func mapId(rec map[string]interface{}) string {
+	switch rtype := mapString(rec, "type"); rtype {
+	case "service":
+		return fmt.Sprintf("service %s/%s", mapString(rec, "system"), mapString(rec, "urn"))
+	case "notation":
+		return fmt.Sprintf("notation %s", mapString(rec, "system"))
+	case "presentation":
+		return "presentation"
+	default:
+		return fmt.Sprintf("%s %s", rtype, mapString(rec, "hash"))
+	}
+}
+
+// helper function to validate DAS map record, it returns list of problems
+func validateRecord(rec map[string]interface{}) []string {
+	var out []string
+	for _, key := range []string{"hash", "type"} {
+		if mapString(rec, key) == "" {
+			out = append(out, fmt.Sprintf("missing %s", key))
+		}
+	}
+	switch rtype := mapString(rec, "type"); rtype {
+	case "service":
+		for _, key := range []string{"system", "urn", "url", "lookup", "format"} {
+			if mapString(rec, key) == "" {
+				out = append(out, fmt.Sprintf("missing %s", key))
+			}
+		}
+		if _, ok := rec["expire"].(float64); !ok {
+			out = append(out, "missing or non-numeric expire")
+		}
+		items, ok := rec["das_map"].([]interface{})
+		if !ok {
+			out = append(out, "missing das_map")
+		}
+		for idx, item := range items {
+			m, ok := mapRecord(item)
+			if !ok || mapString(m, "das_key") == "" || mapString(m, "rec_key") == "" {
+				out = append(out, fmt.Sprintf("das_map[%d] should have das_key and rec_key", idx))
+			}
+		}
+		if v, ok := rec["instances"]; ok && v != nil {
+			if _, ok := v.([]interface{}); !ok {
+				out = append(out, "instances should be a list")
+			}
+		}
+	case "notation":
+		if mapString(rec, "system") == "" {
+			out = append(out, "missing system")
+		}
+		if _, ok := rec["notations"].([]interface{}); !ok {
+			out = append(out, "missing notations")
+		}
+	case "presentation":
+		if _, ok := mapRecord(rec["presentation"]); !ok {
+			out = append(out, "missing presentation")
+		}
+	case "":
+	default:
+		out = append(out, fmt.Sprintf("unknown type %s", rtype))
+	}
+	return out
+}
+
+// helper function to validate DAS maps records
+func validateMaps(records []MapRecord, issues []MapIssue) []MapIssue {
+	out := append([]MapIssue{}, issues...)
+	seen := make(map[string]int)
+	for _, r := range records {
+		for _, msg := range validateRecord(r.Record) {
+			out = append(out, MapIssue{Line: r.Line, Error: msg})
+		}
+		id := mapId(r.Record)
+		if line, ok := seen[id]; ok {
+			out = append(out, MapIssue{Line: r.Line, Error: fmt.Sprintf("duplicate %s, see line %d", id, line)})
+			continue
+		}
+		seen[id] = r.Line
+	}
+	if len(records) == 0 {
+		out = append(out, MapIssue{Error: "no DAS map records"})
+	} else if _, ok := seen["presentation"]; !ok {
+		out = append(out, MapIssue{Error: "no presentation record"})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Line < out[j].Line })
+	return out
+}
+
+// helper function to list DAS maps of CMS data-services, filters are
+// given as system=pattern, urn=pattern or lookup=pattern with shell wildcards
+func listMaps(records []MapRecord, filters []string) ([]MapEntry, error) {
+	conds := make(map[string]string)
+	for _, f := range filters {
+		arr := strings.SplitN(f, "=", 2)
+		if len(arr) != 2 || !utils.InList(arr[0], []string{"system", "urn", "lookup"}) {
+			return nil, fmt.Errorf("invalid filter '%s', should be system=, urn= or lookup=", f)
+		}
+		if _, err := path.Match(arr[1], ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %v", arr[1], err)
+		}
+		conds[arr[0]] = arr[1]
+	}
+	out := []MapEntry{}
+	for _, r := range records {
+		rec := r.Record
+		if mapString(rec, "type") != "service" {
+			continue
+		}
+		matched := true
+		for key, pat := range conds {
+			if ok, _ := path.Match(pat, mapString(rec, key)); !ok {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+		out = append(out, MapEntry{
+			System:    mapString(rec, "system"),
+			Urn:       mapString(rec, "urn"),
+			Lookup:    mapString(rec, "lookup"),
+			Url:       mapString(rec, "url"),
+			Keys:      mapKeys(rec),
+			Instances: mapStrings(rec, "instances"),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].System != out[j].System {
+			return out[i].System < out[j].System
+		}
+		return out[i].Urn < out[j].Urn
+	})
+	return out, nil
+}
+
+// helper function to find differences between two sets of DAS maps records,
+// hash of the record is not compared since it changes with record content
+func diffMaps(a, b []MapRecord) []MapChange {
+	amap := make(map[string]map[string]interface{})
+	bmap := make(map[string]map[string]interface{})
+	for _, r := range a {
+		amap[mapId(r.Record)] = r.Record
+	}
+	for _, r := range b {
+		bmap[mapId(r.Record)] = r.Record
+	}
+	out := []MapChange{}
+	for _, id := range sortedKeys(amap) {
+		brec, ok := bmap[id]
+		if !ok {
+			out = append(out, MapChange{Id: id, Change: "removed"})
+			continue
+		}
+		arec := amap[id]
+		var fields []string
+		for key := range arec {
+			if _, ok := brec[key]; !ok {
+				fields = append(fields, key)
+			}
+		}
+		for key, val := range brec {
+			if key == "hash" {
+				continue
+			}
+			if v, ok := arec[key]; !ok || !reflect.DeepEqual(v, val) {
+				fields = append(fields, key)
+			}
+		}
+		if len(fields) > 0 {
+			sort.Strings(fields)
+			out = append(out, MapChange{Id: id, Change: "changed", Fields: fields})
+		}
+	}
+	for _, id := range sortedKeys(bmap) {
+		if _, ok := amap[id]; !ok {
+			out = append(out, MapChange{Id: id, Change: "added"})
+		}
+	}
+	return out
+}
+
+// helper function to fetch DAS maps from given source, the source can be
+// either url or local file
+func fetchMaps(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(source, "file://"))
+	}
+	client := &http.Client{Timeout: time.Duration(utils.TIMEOUT) * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch %s, status %s", source, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// helper function to atomically replace file with given data, i.e. we write
+// data to temporary file in the same directory and rename it
+func writeFileAtomic(fname string, data []byte) error {
+	dir := filepath.Dir(fname)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(fname)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fname)
+}
+
+// helper function to fetch DAS maps from given source and store them in
+// given file, the maps are validated before we replace existing file
+func updateMaps(source, fname string) (MapsInfo, error) {
+	data, err := fetchMaps(source)
+	if err != nil {
+		return MapsInfo{}, err
+	}
+	records, issues := parseMaps(data)
+	if issues = validateMaps(records, issues); len(issues) > 0 {
+		return MapsInfo{}, fmt.Errorf("DAS maps from %s are invalid, line %d: %s", source, issues[0].Line, issues[0].Error)
+	}
+	if err := writeFileAtomic(fname, data); err != nil {
+		return MapsInfo{}, err
+	}
+	return mapsInfo(fname)
+}
+
+// helper function to get information about DAS maps file
+func mapsInfo(fname string) (MapsInfo, error) {
+	stat, err := os.Stat(fname)
+	if err != nil {
+		return MapsInfo{}, err
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return MapsInfo{}, err
+	}
+	records, _ := parseMaps(data)
+	var systems []string
+	for _, r := range records {
+		if srv := mapString(r.Record, "system"); srv != "" && !utils.InList(srv, systems) {
+			systems = append(systems, srv)
+		}
+	}
+	sort.Strings(systems)
+	age := time.Since(stat.ModTime())
+	return MapsInfo{
+		File:     fname,
+		Version:  mapsVersion(data),
+		Modified: stat.ModTime(),
+		Age:      age.Round(time.Second).String(),
+		Stale:    age > dasMapsTTL,
+		Records:  len(records),
+		Systems:  systems,
+	}, nil
+}
+
+// helper function to print information about DAS maps
+func (i MapsInfo) Print(w io.Writer) {
+	fmt.Fprintf(w, "file     : %s\n", i.File)
+	fmt.Fprintf(w, "version  : %s\n", i.Version)
+	fmt.Fprintf(w, "modified : %s (%s ago)\n", i.Modified.Format(time.RFC3339), i.Age)
+	fmt.Fprintf(w, "stale    : %v\n", i.Stale)
+	fmt.Fprintf(w, "records  : %d\n", i.Records)
+	fmt.Fprintf(w, "systems  : %s\n", strings.Join(i.Systems, ", "))
+	if i.Outdated != nil {
+		fmt.Fprintf(w, "source   : %s\n", i.Source)
+		fmt.Fprintf(w, "remote   : %s\n", i.Remote)
+		fmt.Fprintf(w, "outdated : %v\n", *i.Outdated)
+	}
+}
+
+// helper function to print list of DAS maps
+func printMapEntries(w io.Writer, entries []MapEntry) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYSTEM\tURN\tLOOKUP\tDAS KEYS\tURL")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.System, e.Urn, e.Lookup, strings.Join(e.Keys, ","), e.Url)
+	}
+	tw.Flush()
+}
+
+// helper function to report error of maps command
+func mapsError(err error) int {
+	fmt.Println("ERROR:", err)
+	return utils.DASQueryError
+}
+
+// helper function to execute maps sub-command, it returns DAS exit code
+func maps(cmd string, args []string, source string, jsonout bool) int {
+	if !utils.InList(cmd, mapsCommands) {
+		fmt.Printf("Usage: dasgoclient maps %s [options] [args]\n", strings.Join(mapsCommands, "|"))
+		return utils.DASQueryError
+	}
+	fname := localMapsFile()
+	switch cmd {
+	case "list":
+		records, _, err := readMaps(fname)
+		if err != nil {
+			return mapsError(err)
+		}
+		entries, err := listMaps(records, args)
+		if err != nil {
+			return mapsError(err)
+		}
+		if jsonout {
+			printJSON(entries)
+		} else {
+			printMapEntries(os.Stdout, entries)
+		}
+	case "validate":
+		if len(args) > 0 {
+			fname = args[0]
+		}
+		records, issues, err := readMaps(fname)
+		if err != nil {
+			return mapsError(err)
+		}
+		issues = validateMaps(records, issues)
+		if jsonout {
+			printJSON(issues)
+		} else {
+			for _, i := range issues {
+				fmt.Printf("%s:%d: %s\n", fname, i.Line, i.Error)
+			}
+			fmt.Printf("%d records, %d errors\n", len(records), len(issues))
+		}
+		if len(issues) > 0 {
+			return utils.DASQueryError
+		}
+	case "diff":
+		if len(args) == 0 || len(args) > 2 {
+			fmt.Println("Usage: dasgoclient maps diff [old] new")
+			return utils.DASQueryError
+		}
+		if len(args) == 1 {
+			args = []string{fname, args[0]}
+		}
+		old, _, err := readMaps(args[0])
+		if err != nil {
+			return mapsError(err)
+		}
+		cur, _, err := readMaps(args[1])
+		if err != nil {
+			return mapsError(err)
+		}
+		changes := diffMaps(old, cur)
+		if jsonout {
+			printJSON(changes)
+			return 0
+		}
+		marks := map[string]string{"added": "+", "removed": "-", "changed": "~"}
+		for _, c := range changes {
+			if len(c.Fields) > 0 {
+				fmt.Printf("%s %s: %s\n", marks[c.Change], c.Id, strings.Join(c.Fields, ", "))
+			} else {
+				fmt.Printf("%s %s\n", marks[c.Change], c.Id)
+			}
+		}
+	case "fetch":
+		var prev string
+		if data, err := os.ReadFile(fname); err == nil {
+			prev = mapsVersion(data)
+		}
+		info, err := updateMaps(source, fname)
+		if err != nil {
+			return mapsError(err)
+		}
+		info.Source = source
+		info.Remote = info.Version
+		if jsonout {
+			printJSON(info)
+		} else if prev == info.Version {
+			fmt.Printf("DAS maps %s are up-to-date, version %s\n", fname, info.Version)
+		} else {
+			fmt.Printf("DAS maps %s updated from %s, version %s, %d records\n", fname, source, info.Version, info.Records)
+		}
+	case "info", "check":
+		info, err := mapsInfo(fname)
+		if err != nil {
+			return mapsError(err)
+		}
+		if cmd == "check" {
+			data, err := fetchMaps(source)
+			if err != nil {
+				return mapsError(err)
+			}
+			info.Source = source
+			info.Remote = mapsVersion(data)
+			outdated := info.Remote != info.Version
+			info.Outdated = &outdated
+		}
+		if jsonout {
+			printJSON(info)
+		} else {
+			info.Print(os.Stdout)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateMaps tests validation of DAS maps
+func TestValidateMaps(t *testing.T) {
+	assert := assert.New(t)
+	records, issues, err := readMaps("testdata/das_maps.js")
+	assert.Nil(err)
+	assert.Equal(104, len(records))
+	assert.Equal(0, len(validateMaps(records, issues)))
+
+	data := `{"hash": "x", "type": "service", "system": "dbs3", "urn": "datasets", "das_map": [{"das_key": "dataset"}]}
+{"hash": "y", "type": "service", "system": "dbs3", "urn": "datasets", "url": "u", "lookup": "dataset", "format": "JSON", "expire": 900, "das_map": []}
+{bad`
+	records, issues = parseMaps([]byte(data))
+	issues = validateMaps(records, issues)
+	var msgs []string
+	for _, i := range issues {
+		msgs = append(msgs, i.Error)
+	}
+	assert.Contains(msgs, "missing url")
+	assert.Contains(msgs, "missing or non-numeric expire")
+	assert.Contains(msgs, "das_map[0] should have das_key and rec_key")
+	assert.Contains(msgs, "duplicate service dbs3/datasets, see line 1")
+	assert.Contains(msgs, "no presentation record")
+	assert.Equal(3, issues[len(issues)-1].Line)
+}
+
+// TestListMaps tests listing of DAS maps
+func TestListMaps(t *testing.T) {
+	assert := assert.New(t)
+	records, _, err := readMaps("testdata/das_maps.js")
+	assert.Nil(err)
+	entries, err := listMaps(records, []string{"system=runregistry", "urn=rr_*"})
+	assert.Nil(err)
+	assert.Equal(2, len(entries))
+	assert.Equal("rr_xmlrpc", entries[0].Urn)
+	assert.Equal([]string{"run", "date"}, entries[1].Keys)
+
+	entries, err = listMaps(records, []string{"lookup=nothing"})
+	assert.Nil(err)
+	assert.Equal(0, len(entries))
+
+	_, err = listMaps(records, []string{"api=datasets"})
+	assert.NotNil(err)
+}
+
+// TestDiffMaps tests differences between DAS maps
+func TestDiffMaps(t *testing.T) {
+	assert := assert.New(t)
+	a, _ := parseMaps([]byte(`{"hash": "1", "type": "service", "system": "dbs3", "urn": "datasets", "expire": 900}
+{"hash": "2", "type": "service", "system": "dbs3", "urn": "files", "expire": 900}
+{"hash": "3", "type": "notation", "system": "dbs3", "notations": []}`))
+	b, _ := parseMaps([]byte(`{"hash": "4", "type": "service", "system": "dbs3", "urn": "datasets", "expire": 3600}
+{"hash": "3", "type": "notation", "system": "dbs3", "notations": []}
+{"hash": "5", "type": "service", "system": "rucio", "urn": "files", "expire": 900}`))
+	changes := diffMaps(a, b)
+	assert.Equal([]MapChange{
+		{Id: "service dbs3/datasets", Change: "changed", Fields: []string{"expire"}},
+		{Id: "service dbs3/files", Change: "removed"},
+		{Id: "service rucio/files", Change: "added"},
+	}, changes)
+	assert.Equal(0, len(diffMaps(a, a)))
+}
+
+// TestUpdateMaps tests fetching of DAS maps with atomic replacement
+func TestUpdateMaps(t *testing.T) {
+	assert := assert.New(t)
+	data, err := os.ReadFile("testdata/das_maps.js")
+	assert.Nil(err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bad":
+			w.Write([]byte("{bad"))
+			return
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	fname := filepath.Join(t.TempDir(), ".dasmaps", "das_maps_dbs_prod.js")
+	info, err := updateMaps(server.URL+"/maps", fname)
+	assert.Nil(err)
+	assert.Equal(mapsVersion(data), info.Version)
+	assert.Equal(104, info.Records)
+	assert.False(info.Stale)
+	assert.Contains(info.Systems, "dbs3")
+
+	// invalid maps should not replace existing ones
+	_, err = updateMaps(server.URL+"/bad", fname)
+	assert.True(strings.Contains(err.Error(), "are invalid"))
+	saved, err := os.ReadFile(fname)
+	assert.Nil(err)
+	assert.Equal(data, saved)
+	files, err := os.ReadDir(filepath.Dir(fname))
+	assert.Nil(err)
+	assert.Equal(1, len(files))
+
+	_, err = updateMaps(server.URL+"/missing", fname)
+	assert.True(strings.Contains(err.Error(), "404"))
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// default histogram buckets in seconds
+var metricBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// counterVec represents Prometheus counter with set of labels
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+	values map[string]float64
+}
+
+// histogramVec represents Prometheus histogram with set of labels
+type histogramVec struct {
+	name   string
+	help   string
+	labels []string
+	counts map[string][]uint64
+	sums   map[string]float64
+	totals map[string]uint64
+}
+
+// Metrics holds dasgoclient counters and histograms and exposes them in
+// Prometheus text exposition format
+type Metrics struct {
+	Queries      *counterVec
+	QueryTime    *histogramVec
+	Upstream     *counterVec
+	UpstreamTime *histogramVec
+	Retries      *counterVec
+	Cache        *counterVec
+	ExitCodes    *counterVec
+	start        time.Time
+	mutex        sync.Mutex
+}
+
+// attemptsKey is context key of number of HTTP calls of url fetch
+type attemptsKey struct{}
+
+// global metrics, nil means metrics are not collected
+var dasMetrics *Metrics
+
+// NewMetrics returns new Metrics object
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Queries: &counterVec{
+			name:   "dasgoclient_queries_total",
+			help:   "Number of DAS queries by key and status",
+			labels: []string{"key", "status"},
+			values: make(map[string]float64)},
+		QueryTime: newHistogramVec(
+			"dasgoclient_query_duration_seconds",
+			"Time spent to process DAS query by key",
+			[]string{"key"}),
+		Upstream: &counterVec{
+			name:   "dasgoclient_upstream_requests_total",
+			help:   "Number of upstream HTTP requests by CMS data-service and HTTP status code",
+			labels: []string{"service", "code"},
+			values: make(map[string]float64)},
+		UpstreamTime: newHistogramVec(
+			"dasgoclient_upstream_request_duration_seconds",
+			"Latency of upstream HTTP requests by CMS data-service",
+			[]string{"service"}),
+		Retries: &counterVec{
+			name:   "dasgoclient_upstream_retries_total",
+			help:   "Number of retried upstream HTTP requests by CMS data-service",
+			labels: []string{"service"},
+			values: make(map[string]float64)},
+		Cache: &counterVec{
+			name:   "dasgoclient_cache_requests_total",
+			help:   "Number of cache look-ups by cache name and result (hit or miss)",
+			labels: []string{"cache", "result"},
+			values: make(map[string]float64)},
+		ExitCodes: &counterVec{
+			name:   "dasgoclient_exit_codes_total",
+			help:   "Number of DAS exit codes by code and name",
+			labels: []string{"code", "name"},
+			values: make(map[string]float64)},
+		start: time.Now(),
+	}
+}
+
+// helper function to create new histogram
+func newHistogramVec(name, help string, labels []string) *histogramVec {
+	return &histogramVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		counts: make(map[string][]uint64),
+		sums:   make(map[string]float64),
+		totals: make(map[string]uint64),
+	}
+}
+
+// helper function to make map key out of label values
+func labelKey(vals ...string) string {
+	return strings.Join(vals, "\x00")
+}
+
+// helper function to escape Prometheus label value
+func labelValue(val string) string {
+	val = strings.Replace(val, `\`, `\\`, -1)
+	val = strings.Replace(val, `"`, `\"`, -1)
+	return strings.Replace(val, "\n", `\n`, -1)
+}
+
+// helper function to format labels of given key
+func formatLabels(names []string, key string, extra ...string) string {
+	var out []string
+	if len(names) > 0 {
+		for i, v := range strings.Split(key, "\x00") {
+			out = append(out, fmt.Sprintf("%s=\"%s\"", names[i], labelValue(v)))
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		out = append(out, fmt.Sprintf("%s=\"%s\"", extra[i], extra[i+1]))
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(out, ",") + "}"
+}
+
+// helper function to return sorted keys of the map
+func sortedKeys[T any](m map[string]T) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// add increments counter with given label values
+func (c *counterVec) add(val float64, labels ...string) {
+	c.values[labelKey(labels...)] += val
+}
+
+// observe adds observation to histogram with given label values
+func (h *histogramVec) observe(val float64, labels ...string) {
+	key := labelKey(labels...)
+	counts, ok := h.counts[key]
+	if !ok {
+		counts = make([]uint64, len(metricBuckets))
+		h.counts[key] = counts
+	}
+	for i, b := range metricBuckets {
+		if val <= b {
+			counts[i] += 1
+		}
+	}
+	h.sums[key] += val
+	h.totals[key] += 1
+}
+
+// write writes counter in Prometheus text format
+func (c *counterVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, k := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %v\n", c.name, formatLabels(c.labels, k), c.values[k])
+	}
+}
+
+// write writes histogram in Prometheus text format
+func (h *histogramVec) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, k := range sortedKeys(h.counts) {
+		for i, b := range metricBuckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, k, "le", fmt.Sprintf("%v", b)), h.counts[k][i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, k, "le", "+Inf"), h.totals[k])
+		fmt.Fprintf(w, "%s_sum%s %v\n", h.name, formatLabels(h.labels, k), h.sums[k])
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, k), h.totals[k])
+	}
+}
+
+// Query records DAS query with given key, DAS exit code and processing time
+func (m *Metrics) Query(key string, ecode int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	if key == "" {
+		key = "unknown"
+	}
+	status := "ok"
+	if ecode != 0 {
+		status = "error"
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Queries.add(1, key, status)
+	m.QueryTime.observe(elapsed.Seconds(), key)
+	m.ExitCodes.add(1, fmt.Sprintf("%d", ecode), dasExitCodeName(ecode))
+}
+
+// Attempt records upstream HTTP call with its status code and latency,
+// attempt is sequence number of HTTP call of url fetch, i.e. attempts
+// beyond the first one are retries
+func (m *Metrics) Attempt(rurl string, status int, elapsed time.Duration, attempt int) {
+	if m == nil {
+		return
+	}
+	service := urlService(rurl)
+	code := fmt.Sprintf("%d", status)
+	if status == 0 {
+		code = "error"
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Upstream.add(1, service, code)
+	m.UpstreamTime.observe(elapsed.Seconds(), service)
+	if attempt > 1 {
+		m.Retries.add(1, service)
+	}
+}
+
+// helper function to get context which counts HTTP calls of single url
+// fetch, the counter is released along with the context once url is fetched
+func contextWithAttempts(ctx context.Context) context.Context {
+	if dasMetrics == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, attemptsKey{}, new(int32))
+}
+
+// CacheLookup records cache hit or miss of given cache
+func (m *Metrics) CacheLookup(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Cache.add(1, cache, result)
+}
+
+// Write writes all metrics in Prometheus text format
+func (m *Metrics) Write(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Queries.write(w)
+	m.QueryTime.write(w)
+	m.Upstream.write(w)
+	m.UpstreamTime.write(w)
+	m.Retries.write(w)
+	m.Cache.write(w)
+	m.ExitCodes.write(w)
+	fmt.Fprintf(w, "# HELP dasgoclient_uptime_seconds Time since dasgoclient start\n# TYPE dasgoclient_uptime_seconds gauge\n")
+	fmt.Fprintf(w, "dasgoclient_uptime_seconds %v\n", time.Since(m.start).Seconds())
+}
+
+// ServeHTTP implements http.Handler interface for /metrics end-point
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.Write(w)
+}
+
+// helper function to start metrics server on given address
+func startMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", dasMetrics)
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Println("ERROR: unable to start metrics server", err)
+		}
+	}()
+}
+
+// metricsTransport is HTTP transport which records upstream HTTP calls
+// in dasgoclient metrics
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	time0 := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	// HTTP calls outside of url fetch are never retried
+	attempt := 1
+	if n, ok := req.Context().Value(attemptsKey{}).(*int32); ok {
+		attempt = int(atomic.AddInt32(n, 1))
+	}
+	dasMetrics.Attempt(req.URL.String(), status, time.Since(time0), attempt)
+	return resp, err
+}
+
+// helper function to wrap HTTP client transport with metrics transport
+func metricsClient(client *http.Client) *http.Client {
+	if dasMetrics == nil {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &metricsTransport{base: base}
+	return client
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to parse Prometheus text format into map of sample
+// values keyed by metric name with its labels
+func parseMetrics(t *testing.T, data string) map[string]float64 {
+	samples := make(map[string]float64)
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.LastIndex(line, " ")
+		val, err := strconv.ParseFloat(line[idx+1:], 64)
+		assert.NoError(t, err, line)
+		samples[line[:idx]] = val
+	}
+	return samples
+}
+
+// TestMetricsCounters tests counters of DAS queries and exit codes
+func TestMetricsCounters(t *testing.T) {
+	assert := assert.New(t)
+	m := NewMetrics()
+	m.Query("dataset", 0, 10*time.Millisecond)
+	m.Query("dataset", 0, 20*time.Millisecond)
+	m.Query("file", utils.DASParserError, time.Millisecond)
+	m.Query("", utils.DBSError, time.Millisecond)
+	m.CacheLookup("dasmaps", true)
+	m.CacheLookup("dasmaps", false)
+	m.CacheLookup("dasmaps", false)
+
+	var buf bytes.Buffer
+	m.Write(&buf)
+	out := buf.String()
+	assert.Contains(out, "# HELP dasgoclient_queries_total Number of DAS queries by key and status\n# TYPE dasgoclient_queries_total counter\n")
+	assert.Contains(out, "# TYPE dasgoclient_query_duration_seconds histogram\n")
+	assert.Contains(out, "# TYPE dasgoclient_uptime_seconds gauge\n")
+
+	samples := parseMetrics(t, out)
+	assert.Equal(float64(2), samples[`dasgoclient_queries_total{key="dataset",status="ok"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_queries_total{key="file",status="error"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_queries_total{key="unknown",status="error"}`])
+	assert.Equal(float64(2), samples[`dasgoclient_exit_codes_total{code="0",name="ok"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_exit_codes_total{code="17",name="DAS parser error"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_exit_codes_total{code="2",name="DBS upstream error"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_cache_requests_total{cache="dasmaps",result="hit"}`])
+	assert.Equal(float64(2), samples[`dasgoclient_cache_requests_total{cache="dasmaps",result="miss"}`])
+
+	// nil metrics do not record anything
+	var nilMetrics *Metrics
+	nilMetrics.Query("dataset", 0, time.Second)
+	nilMetrics.Attempt("https://cmsweb.cern.ch/dbs", 200, time.Second, 1)
+	nilMetrics.CacheLookup("dasmaps", true)
+}
+
+// TestMetricsHistogram tests consistency of histogram buckets, sum and count
+func TestMetricsHistogram(t *testing.T) {
+	assert := assert.New(t)
+	m := NewMetrics()
+	for _, val := range []float64{0.001, 0.02, 0.02, 0.7, 3, 120} {
+		m.Query("dataset", 0, time.Duration(val*float64(time.Second)))
+	}
+	var buf bytes.Buffer
+	m.Write(&buf)
+	samples := parseMetrics(t, buf.String())
+
+	name := "dasgoclient_query_duration_seconds"
+	var prev float64
+	for _, b := range metricBuckets {
+		val, ok := samples[name+`_bucket{key="dataset",le="`+strconv.FormatFloat(b, 'g', -1, 64)+`"}`]
+		assert.True(ok, b)
+		assert.True(val >= prev, b)
+		prev = val
+	}
+	assert.Equal(float64(1), samples[name+`_bucket{key="dataset",le="0.005"}`])
+	assert.Equal(float64(3), samples[name+`_bucket{key="dataset",le="0.025"}`])
+	assert.Equal(float64(4), samples[name+`_bucket{key="dataset",le="1"}`])
+	assert.Equal(float64(5), samples[name+`_bucket{key="dataset",le="60"}`])
+	assert.Equal(float64(6), samples[name+`_bucket{key="dataset",le="+Inf"}`])
+	assert.Equal(float64(6), samples[name+`_count{key="dataset"}`])
+	assert.InDelta(123.741, samples[name+`_sum{key="dataset"}`], 1e-6)
+}
+
+// TestMetricsLabels tests escaping of label values
+func TestMetricsLabels(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(`a\\b\"c\nd`, labelValue("a\\b\"c\nd"))
+	assert.Equal(`{key="dataset"}`, formatLabels([]string{"key"}, labelKey("dataset")))
+	assert.Equal(`{service="dbs",code="200"}`, formatLabels([]string{"service", "code"}, labelKey("dbs", "200")))
+	assert.Equal(`{key="file",le="0.5"}`, formatLabels([]string{"key"}, labelKey("file"), "le", "0.5"))
+	assert.Equal("", formatLabels(nil, ""))
+
+	m := NewMetrics()
+	m.Query("file \"a\\b\"\nrun", 0, time.Millisecond)
+	var buf bytes.Buffer
+	m.Write(&buf)
+	assert.Contains(buf.String(), `dasgoclient_queries_total{key="file \"a\\b\"\nrun",status="ok"} 1`+"\n")
+}
+
+// TestMetricsRetries tests counting of upstream calls and their retries
+func TestMetricsRetries(t *testing.T) {
+	assert := assert.New(t)
+	m := NewMetrics()
+	rurl := "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blocks?dataset=/a/b/c"
+	m.Attempt(rurl, 0, time.Millisecond, 1)
+	m.Attempt(rurl, 503, time.Millisecond, 2)
+	m.Attempt(rurl, 200, time.Millisecond, 3)
+	// the same url fetched again by next query is not a retry
+	m.Attempt(rurl, 200, time.Millisecond, 1)
+	m.Attempt("http://cms-rucio.cern.ch/replicas/cms/?name=/dbs/a", 200, time.Millisecond, 1)
+
+	var buf bytes.Buffer
+	m.Write(&buf)
+	samples := parseMetrics(t, buf.String())
+	assert.Equal(float64(1), samples[`dasgoclient_upstream_requests_total{service="dbs",code="error"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_upstream_requests_total{service="dbs",code="503"}`])
+	assert.Equal(float64(2), samples[`dasgoclient_upstream_requests_total{service="dbs",code="200"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_upstream_requests_total{service="rucio",code="200"}`])
+	assert.Equal(float64(2), samples[`dasgoclient_upstream_retries_total{service="dbs"}`])
+	_, ok := samples[`dasgoclient_upstream_retries_total{service="rucio"}`]
+	assert.False(ok)
+	assert.Equal(float64(4), samples[`dasgoclient_upstream_request_duration_seconds_count{service="dbs"}`])
+}
+
+// TestMetricsHandler tests /metrics end-point and metrics HTTP transport
+func TestMetricsHandler(t *testing.T) {
+	assert := assert.New(t)
+	dasMetrics = NewMetrics()
+	defer func() { dasMetrics = nil }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client := metricsClient(&http.Client{})
+	resp, err := client.Get(server.URL + "/dbs/prod/global/DBSReader/datasets")
+	assert.NoError(err)
+	resp.Body.Close()
+
+	w := httptest.NewRecorder()
+	dasMetrics.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal("text/plain; version=0.0.4", w.Header().Get("Content-Type"))
+	samples := parseMetrics(t, w.Body.String())
+	assert.Equal(float64(1), samples[`dasgoclient_upstream_requests_total{service="dbs",code="404"}`])
+	_, ok := samples[`dasgoclient_upstream_retries_total{service="dbs"}`]
+	assert.False(ok)
+
+	// HTTP calls of url fetch are counted per fetch, i.e. concurrent
+	// fetches of the same url are not retries of each other
+	ctx1 := contextWithAttempts(context.Background())
+	ctx2 := contextWithAttempts(context.Background())
+	for _, ctx := range []context.Context{ctx1, ctx2, ctx1} {
+		resp, err := contextClient(ctx, client).Get(server.URL + "/dbs/prod/global/DBSReader/datasets")
+		assert.NoError(err)
+		resp.Body.Close()
+	}
+	w = httptest.NewRecorder()
+	dasMetrics.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	samples = parseMetrics(t, w.Body.String())
+	assert.Equal(float64(4), samples[`dasgoclient_upstream_requests_total{service="dbs",code="404"}`])
+	assert.Equal(float64(1), samples[`dasgoclient_upstream_retries_total{service="dbs"}`])
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+	"github.com/klauspost/compress/zstd"
+)
+
+// OutputFile represents output file which is written to temporary file and
+// renamed to its name when all results are written, i.e. readers never see
+// partially written results
+type OutputFile struct {
+	Name   string         // name of output file
+	tmp    *os.File       // temporary file in the same directory
+	buf    *bufio.Writer  // buffer of temporary file
+	writer io.Writer      // writer of (compressed) results
+	closer io.WriteCloser // compression writer if any
+}
+
+// OutputMetadata represents sidecar metadata file of output file
+type OutputMetadata struct {
+	Query       string `json:"query"`
+	Instance    string `json:"instance"`
+	Timestamp   int64  `json:"timestamp"`
+	Records     int    `json:"nresults"`
+	ECode       int    `json:"ecode"`
+	Format      string `json:"format,omitempty"`
+	Compression string `json:"compression,omitempty"`
+	File        string `json:"file"`
+}
+
+// helper function to get compression of output file by its extension
+func outputCompression(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gz":
+		return "gzip"
+	case ".zst", ".zstd":
+		return "zstd"
+	}
+	return ""
+}
+
+// helper function to read content of output file, it is decompressed
+// according to extension of its name, i.e. .gz or .zst
+func readOutputFile(name string) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var reader io.Reader = bufio.NewReader(file)
+	switch outputCompression(name) {
+	case "gzip":
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case "zstd":
+		zr, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	return io.ReadAll(reader)
+}
+
+// helper function to create output file, results are compressed according
+// to extension of its name, i.e. .gz or .zst
+func createOutput(name string) (*OutputFile, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	out := &OutputFile{Name: name, tmp: tmp, buf: bufio.NewWriter(tmp)}
+	out.writer = out.buf
+	switch outputCompression(name) {
+	case "gzip":
+		out.closer = gzip.NewWriter(out.buf)
+	case "zstd":
+		if out.closer, err = zstd.NewWriter(out.buf); err != nil {
+			out.Abort()
+			return nil, err
+		}
+	}
+	if out.closer != nil {
+		out.writer = out.closer
+	}
+	return out, nil
+}
+
+// Write implements io.Writer interface
+func (o *OutputFile) Write(data []byte) (int, error) {
+	return o.writer.Write(data)
+}
+
+// Commit writes all buffered results and renames temporary file to name of
+// output file
+func (o *OutputFile) Commit() error {
+	if o.closer != nil {
+		if err := o.closer.Close(); err != nil {
+			o.Abort()
+			return err
+		}
+	}
+	for _, step := range []func() error{o.buf.Flush, o.tmp.Sync, o.tmp.Close} {
+		if err := step(); err != nil {
+			o.Abort()
+			return err
+		}
+	}
+	if err := os.Chmod(o.tmp.Name(), 0644); err != nil {
+		os.Remove(o.tmp.Name())
+		return err
+	}
+	if err := os.Rename(o.tmp.Name(), o.Name); err != nil {
+		os.Remove(o.tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort removes temporary file of output file
+func (o *OutputFile) Abort() {
+	o.tmp.Close()
+	os.Remove(o.tmp.Name())
+}
+
+// helper function to write results of DAS query into output file along with
+// its sidecar metadata file, it returns DAS exit code. Output file is left
+// untouched if DAS query failed or its results can't be written.
+func writeOutput(res Result, opts Options) int {
+	// failed DAS query has no results, its error is reported on stdout
+	if res.Fatal {
+		return writeResult(os.Stdout, res, opts)
+	}
+	out, err := createOutput(opts.Output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: unable to create output file:", err)
+		return utils.DASQueryError
+	}
+	ecode, nrec, err := writeRecords(out, res, opts)
+	if err != nil {
+		out.Abort()
+		fmt.Fprintln(os.Stderr, "ERROR: unable to write output file:", err)
+		return ecode
+	}
+	if err := out.Commit(); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: unable to write output file:", err)
+		return utils.DASServerError
+	}
+	meta := OutputMetadata{
+		Query:       res.Query.Query,
+		Instance:    res.Query.Instance,
+		Timestamp:   time.Now().Unix(),
+		Records:     nrec,
+		ECode:       ecode,
+		Format:      strings.ToLower(opts.Format),
+		Compression: outputCompression(opts.Output),
+		File:        filepath.Base(opts.Output),
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(opts.Output+".meta.json", append(data, '\n'))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: unable to write metadata file:", err)
+		return utils.DASServerError
+	}
+	return ecode
+}
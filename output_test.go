@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to read content of (compressed) output file
+func readOutput(t *testing.T, name string) string {
+	data, err := readOutputFile(name)
+	assert.Nil(t, err)
+	return string(data)
+}
+
+// TestOutputFile tests atomic and compressed output files
+func TestOutputFile(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	for _, fname := range []string{"out.json", "out.json.gz", "out.csv.zst"} {
+		name := filepath.Join(dir, fname)
+		out, err := createOutput(name)
+		assert.Nil(err)
+		_, err = io.WriteString(out, "hello world\n")
+		assert.Nil(err)
+		// output file appears only when all results are written
+		_, err = os.Stat(name)
+		assert.True(os.IsNotExist(err))
+		assert.Nil(out.Commit())
+		assert.Equal("hello world\n", readOutput(t, name))
+	}
+	out, err := createOutput(filepath.Join(dir, "aborted.json"))
+	assert.Nil(err)
+	io.WriteString(out, "partial")
+	out.Abort()
+	files, err := os.ReadDir(dir)
+	assert.Nil(err)
+	assert.Equal(3, len(files))
+
+	_, err = createOutput(filepath.Join(dir, "missing", "out.json"))
+	assert.NotNil(err)
+}
+
+// TestWriteOutput tests output file of DAS query along with its metadata
+func TestWriteOutput(t *testing.T) {
+	assert := assert.New(t)
+	dasquery, _, err, _ := parseDASQuery("file dataset=/a/b/c", "prod/global", []string{"file", "dataset"})
+	assert.Equal("", err)
+	res := Result{
+		Query:      dasquery,
+		Records:    []mongo.DASRecord{fileRecord("/store/a.root", 100, "/a/b/c#1"), fileRecord("/store/b.root", 200, "/a/b/c#1")},
+		SelectKeys: [][]string{{"file", "name"}},
+	}
+	name := filepath.Join(t.TempDir(), "files.csv.gz")
+	assert.Equal(0, writeOutput(res, Options{Format: "csv", Output: name}))
+	assert.Equal("file.name\n/store/a.root\n/store/b.root\n", readOutput(t, name))
+
+	var meta OutputMetadata
+	data, e := os.ReadFile(name + ".meta.json")
+	assert.Nil(e)
+	assert.Nil(json.Unmarshal(data, &meta))
+	assert.Equal("file dataset=/a/b/c", meta.Query)
+	assert.Equal("prod/global", meta.Instance)
+	assert.Equal(2, meta.Records)
+	assert.Equal(0, meta.ECode)
+	assert.Equal("csv", meta.Format)
+	assert.Equal("gzip", meta.Compression)
+	assert.Equal("files.csv.gz", meta.File)
+	assert.NotEqual(int64(0), meta.Timestamp)
+}
+
+// TestWriteOutputFailures tests that output file is written only for
+// results of DAS query and its metadata counts written records
+func TestWriteOutputFailures(t *testing.T) {
+	assert := assert.New(t)
+	dasquery, _, err, _ := parseDASQuery("file dataset=/a/b/c", "prod/global", []string{"file", "dataset"})
+	assert.Equal("", err)
+	res := Result{
+		Query:      dasquery,
+		Records:    []mongo.DASRecord{fileRecord("/store/a.root", 100, "/a/b/c#1"), fileRecord("/store/b.root", 200, "/a/b/c#1")},
+		SelectKeys: [][]string{{"file", "name"}},
+	}
+	dir := t.TempDir()
+
+	// metadata counts records selected by idx and limit
+	name := filepath.Join(dir, "files.txt")
+	assert.Equal(0, writeOutput(res, Options{Output: name, Idx: 1}))
+	assert.Equal("/store/b.root\n", readOutput(t, name))
+	var meta OutputMetadata
+	data, e := os.ReadFile(name + ".meta.json")
+	assert.Nil(e)
+	assert.Nil(json.Unmarshal(data, &meta))
+	assert.Equal(1, meta.Records)
+
+	// failed template and DAS query leave no output files
+	for _, fname := range []string{"template.txt", "fatal.txt"} {
+		opts := Options{Output: filepath.Join(dir, fname)}
+		res := res
+		if fname == "template.txt" {
+			opts.Template = `{{template "missing"}}`
+		} else {
+			res.ECode = utils.DASParserError
+			res.Fatal = true
+		}
+		assert.NotEqual(0, writeOutput(res, opts), fname)
+	}
+	files, e := os.ReadDir(dir)
+	assert.Nil(e)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.Equal([]string{"files.txt", "files.txt.meta.json"}, names)
+}
+
+// TestWriteOutputMessages tests that warnings and errors of DAS query are not
+// written into output file
+func TestWriteOutputMessages(t *testing.T) {
+	assert := assert.New(t)
+	dasquery, _, err, _ := parseDASQuery("file dataset=/a/b/c", "prod/global", []string{"file", "dataset"})
+	assert.Equal("", err)
+	res := Result{
+		Query:    dasquery,
+		Records:  []mongo.DASRecord{fileRecord("/store/a.root", 100, "/a/b/c#1"), fileRecord("/store/b.root", 200, "/a/b/c#1")},
+		ECode:    utils.RucioError,
+		Error:    utils.RucioErrorName,
+		Warnings: []string{"WARNING: No site records found in Rucio, will look-up original sites in DBS"},
+	}
+	res.Query.Detail = true
+	dir := t.TempDir()
+	for _, format := range []string{"", "ndjson", "csv"} {
+		name := filepath.Join(dir, "files."+format+".json")
+		opts := Options{Output: name, Format: format, JSON: format == ""}
+		assert.Equal(utils.RucioError, writeOutput(res, opts), format)
+		data := readOutput(t, name)
+		assert.NotContains(data, "WARNING", format)
+		assert.NotContains(data, "ERROR", format)
+		if format == "" {
+			var records []map[string]interface{}
+			assert.Nil(json.Unmarshal([]byte(data), &records))
+			assert.Equal(2, len(records))
+		}
+
+		var meta OutputMetadata
+		content, e := os.ReadFile(name + ".meta.json")
+		assert.Nil(e)
+		assert.Nil(json.Unmarshal(content, &meta))
+		assert.Equal(utils.RucioError, meta.ECode)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/parquet-go/parquet-go"
+)
+
+// number of rows in parquet row group, row groups are written to the output
+// as soon as they are filled
+const parquetRowGroupSize = 100000
+
+// DAS record attributes which are not exported to parquet files
+var parquetIgnored = []string{"das", "qhash"}
+
+// ParquetField represents inferred type of attribute of DAS records, kind is
+// one of group, int64, double, boolean, string, timestamp or json
+type ParquetField struct {
+	Kind     string                   // type of attribute values
+	Repeated bool                     // attribute holds list of values
+	Fields   map[string]*ParquetField // fields of group attribute
+}
+
+// helper function to merge two kinds of attribute values, e.g. integer and
+// floating point numbers yield double values
+func mergeKinds(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	case a == "json" || b == "json" || a == "group" || b == "group":
+		return "json"
+	case (a == "int64" && b == "double") || (a == "double" && b == "int64"):
+		return "double"
+	case a == "timestamp" && (b == "int64" || b == "double"):
+		return a
+	case b == "timestamp" && (a == "int64" || a == "double"):
+		return b
+	}
+	return "string"
+}
+
+// helper function to get kind of plain value of given attribute, numeric
+// values of timestamp attributes, e.g. creation_date, are timestamps
+func valueKind(attr string, val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number, float64, int64, int:
+		if timeAttribute(attr) {
+			return "timestamp"
+		}
+		if n, ok := v.(json.Number); ok {
+			if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+				return "double"
+			}
+		}
+		if _, ok := v.(float64); ok {
+			return "double"
+		}
+		return "int64"
+	}
+	return "json"
+}
+
+// helper function to infer type of attribute from its value
+func (f *ParquetField) infer(attr string, val interface{}) {
+	switch v := val.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		f.Kind = mergeKinds(f.Kind, "group")
+		if f.Kind != "group" {
+			return
+		}
+		if f.Fields == nil {
+			f.Fields = make(map[string]*ParquetField)
+		}
+		for key, item := range v {
+			if f.Fields[key] == nil {
+				f.Fields[key] = &ParquetField{}
+			}
+			f.Fields[key].infer(key, item)
+		}
+	case []interface{}:
+		// lists of records, e.g. file records of different CMS
+		// data-services, are repeated groups while lists of mixed
+		// or nested lists are kept in JSON form
+		var nrec int
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}:
+				nrec++
+			case []interface{}:
+				f.Kind = "json"
+			}
+		}
+		if nrec > 0 && nrec < len(v) {
+			f.Kind = "json"
+		}
+		if f.Kind == "json" {
+			return
+		}
+		f.Repeated = true
+		if nrec > 0 {
+			for _, item := range v {
+				f.infer(attr, item)
+			}
+			return
+		}
+		for _, item := range v {
+			f.Kind = mergeKinds(f.Kind, valueKind(attr, item))
+		}
+	default:
+		f.Kind = mergeKinds(f.Kind, valueKind(attr, v))
+	}
+}
+
+// helper function to build parquet group of fields of group attribute
+func (f *ParquetField) group() parquet.Group {
+	group := parquet.Group{}
+	for key, field := range f.Fields {
+		group[key] = field.node()
+	}
+	return group
+}
+
+// helper function to build parquet schema node of the attribute
+func (f *ParquetField) node() parquet.Node {
+	if f.Kind == "group" && len(f.Fields) == 0 {
+		// parquet groups can't be empty, e.g. {} values are kept in JSON form
+		f.Kind = "json"
+	}
+	var node parquet.Node
+	switch f.Kind {
+	case "group":
+		node = f.group()
+	case "int64":
+		node = parquet.Int(64)
+	case "double":
+		node = parquet.Leaf(parquet.DoubleType)
+	case "boolean":
+		node = parquet.Leaf(parquet.BooleanType)
+	case "timestamp":
+		node = parquet.Timestamp(parquet.Millisecond)
+	case "json":
+		node = parquet.JSON()
+	default:
+		node = parquet.String()
+	}
+	// JSON form holds the whole list of values
+	if f.Repeated && f.Kind != "json" {
+		return parquet.Repeated(node)
+	}
+	return parquet.Optional(node)
+}
+
+// helper function to convert plain value into type of the attribute
+func (f *ParquetField) value(val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+	switch f.Kind {
+	case "int64":
+		if n, ok := numberOf(val); ok {
+			if i, ok := n.(int64); ok {
+				return i
+			}
+		}
+	case "double":
+		if n, ok := numericValue(val); ok {
+			return n
+		}
+	case "boolean":
+		if b, ok := val.(bool); ok {
+			return b
+		}
+	case "timestamp":
+		if sec, ok := numericValue(val); ok {
+			return timeOf(sec)
+		}
+	case "json":
+		data, err := json.Marshal(val)
+		if err == nil {
+			return string(data)
+		}
+	default:
+		return formatValue(val)
+	}
+	return nil
+}
+
+// helper function to convert value of DAS record attribute into the form
+// of parquet row, values which don't match inferred type are nulls
+func (f *ParquetField) convert(val interface{}) interface{} {
+	if !f.Repeated || f.Kind == "json" {
+		return f.single(val)
+	}
+	items, ok := val.([]interface{})
+	if !ok {
+		items = []interface{}{val}
+	}
+	var out []interface{}
+	for _, item := range items {
+		if v := f.single(item); v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// helper function to convert single value of DAS record attribute, records
+// of group attributes are converted field by field
+func (f *ParquetField) single(val interface{}) interface{} {
+	if f.Kind != "group" {
+		return f.value(val)
+	}
+	rec, ok := val.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]interface{})
+	for key, field := range f.Fields {
+		out[key] = field.convert(rec[key])
+	}
+	return out
+}
+
+// helper function to convert DAS record into generic record exported to
+// parquet file, grep filter projects it into selected attributes
+func parquetRecord(rec mongo.DASRecord, attrs []string) map[string]interface{} {
+	grec := genericRecord(rec)
+	if len(attrs) > 0 {
+		grec = projectRecord(grec, attrs)
+	}
+	for _, attr := range parquetIgnored {
+		delete(grec, attr)
+	}
+	return grec
+}
+
+// helper function to walk through DAS records exported to parquet file
+// (after -idx, -limit and unique) and pass them to given function, it
+// returns number of walked records
+func walkParquetRecords(res Result, opts Options, fn func(map[string]interface{}) error) (int, error) {
+	var attrs []string
+	if len(res.Query.Aggregators) == 0 {
+		attrs = res.Query.Filters["grep"]
+	}
+	seen := make(map[[sha256.Size]byte]bool)
+	var idx, nrec int
+	for _, rec := range res.Records {
+		if opts.Limit > 0 && nrec == opts.Limit {
+			break
+		}
+		if recordError(rec) != nil {
+			continue
+		}
+		grec := parquetRecord(rec, attrs)
+		if res.Unique {
+			data, _ := json.Marshal(grec)
+			key := sha256.Sum256(data)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		if idx++; idx <= opts.Idx {
+			continue
+		}
+		nrec++
+		if err := fn(grec); err != nil {
+			return 0, err
+		}
+	}
+	return nrec, nil
+}
+
+// helper function to write DAS records into parquet file, the schema of the
+// file is inferred from all exported DAS records, i.e. kinds of attributes
+// are widened to fit all their values, and records are converted and written
+// one by one, it returns number of written records
+func writeParquet(w io.Writer, res Result, opts Options) (int, error) {
+	var pkey string
+	if len(res.Records) > 0 {
+		if das, ok := res.Records[0]["das"].(mongo.DASRecord); ok {
+			pkey, _ = das["primary_key"].(string)
+		}
+	}
+	root := &ParquetField{Kind: "group", Fields: make(map[string]*ParquetField)}
+	walkParquetRecords(res, opts, func(rec map[string]interface{}) error {
+		root.infer("", rec)
+		return nil
+	})
+	writer := parquet.NewWriter(w, parquet.NewSchema("das", root.group()),
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		parquet.Compression(&parquet.Snappy),
+		parquet.KeyValueMetadata("das.query", res.Query.Query),
+		parquet.KeyValueMetadata("das.primary_key", pkey),
+	)
+	nrec, err := walkParquetRecords(res, opts, func(rec map[string]interface{}) error {
+		return writer.Write(root.convert(rec))
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+	return nrec, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to read rows of parquet file
+func parquetRows(t *testing.T, data []byte) []map[string]interface{} {
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	assert.Nil(t, err)
+	reader := parquet.NewReader(bytes.NewReader(data), file.Schema())
+	var rows []map[string]interface{}
+	for {
+		row := make(map[string]interface{})
+		if err := reader.Read(&row); err != nil {
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// TestParquetSchema tests inference of types of DAS records attributes
+func TestParquetSchema(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("double", mergeKinds("int64", "double"))
+	assert.Equal("timestamp", mergeKinds("int64", "timestamp"))
+	assert.Equal("string", mergeKinds("int64", "string"))
+	assert.Equal("json", mergeKinds("group", "string"))
+	assert.Equal("boolean", mergeKinds("", "boolean"))
+
+	root := &ParquetField{Kind: "group", Fields: make(map[string]*ParquetField)}
+	for _, rec := range []mongo.DASRecord{
+		{"file": []mongo.DASRecord{{"name": "/store/a.root", "size": 2063019163, "creation_date": 1325221208.5}}, "lumi": []mongo.DASRecord{{"number": []int64{1, 2}}}},
+		{"file": []mongo.DASRecord{{"name": "/store/b.root", "size": 1.5, "tags": []mongo.DASRecord{{"a": 1}}}}, "lumi": []mongo.DASRecord{{"number": 3}}},
+	} {
+		root.infer("", genericRecord(rec))
+	}
+	file := root.Fields["file"]
+	assert.Equal("group", file.Kind)
+	assert.True(file.Repeated)
+	assert.Equal("string", file.Fields["name"].Kind)
+	assert.Equal("double", file.Fields["size"].Kind)
+	assert.Equal("timestamp", file.Fields["creation_date"].Kind)
+	assert.Equal("group", file.Fields["tags"].Kind)
+	assert.True(file.Fields["tags"].Repeated)
+	assert.Equal(&ParquetField{Kind: "int64", Repeated: true}, root.Fields["lumi"].Fields["number"])
+	assert.Equal([]interface{}{map[string]interface{}{"number": []interface{}{int64(3)}}}, root.Fields["lumi"].convert(map[string]interface{}{"number": 3}))
+
+	// lists of mixed values and nested lists are kept in JSON form
+	for _, val := range []interface{}{
+		[]interface{}{map[string]interface{}{"a": 1}, 2},
+		[]interface{}{[]interface{}{1, 2}},
+	} {
+		field := &ParquetField{}
+		field.infer("", val)
+		assert.Equal("json", field.Kind)
+	}
+}
+
+// TestWriteParquet tests parquet output of DAS records
+func TestWriteParquet(t *testing.T) {
+	assert := assert.New(t)
+	das := mongo.DASRecord{"primary_key": "file.name"}
+	res := Result{Records: []mongo.DASRecord{
+		{"das": das, "qhash": "123", "file": []mongo.DASRecord{{"name": "/store/a.root", "size": 2063019163, "creation_date": 1325221208}}, "lumi": []mongo.DASRecord{{"number": []int64{1, 2, 5}}, {"number": 7}}},
+		{"das": das, "qhash": "123", "file": []mongo.DASRecord{{"name": "/store/b.root"}}},
+	}}
+	res.Query.Query = "file,lumi dataset=/a/b/c"
+	var buf bytes.Buffer
+	nrec, err := writeParquet(&buf, res, Options{})
+	assert.Nil(err)
+	assert.Equal(2, nrec)
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(err)
+	assert.Equal(int64(2), file.NumRows())
+	value, ok := file.Lookup("das.query")
+	assert.True(ok)
+	assert.Equal("file,lumi dataset=/a/b/c", value)
+	var columns []string
+	for _, path := range file.Schema().Columns() {
+		columns = append(columns, path[0]+"."+path[1])
+	}
+	assert.Equal([]string{"file.creation_date", "file.name", "file.size", "lumi.number"}, columns)
+
+	rows := parquetRows(t, buf.Bytes())
+	assert.Equal([]map[string]interface{}{
+		{
+			"file": []interface{}{map[string]interface{}{"name": "/store/a.root", "size": int64(2063019163), "creation_date": int64(1325221208000)}},
+			"lumi": []interface{}{
+				map[string]interface{}{"number": []interface{}{int64(1), int64(2), int64(5)}},
+				map[string]interface{}{"number": []interface{}{int64(7)}},
+			},
+		},
+		{
+			"file": []interface{}{map[string]interface{}{"name": "/store/b.root", "size": nil, "creation_date": nil}},
+			"lumi": []interface{}{},
+		},
+	}, rows)
+
+	buf.Reset()
+	nrec, err = writeParquet(&buf, res, Options{Idx: 1, Limit: 1})
+	assert.Nil(err)
+	assert.Equal(1, nrec)
+
+	// warnings of DAS query are not mixed with parquet data
+	buf.Reset()
+	res.Warnings = []string{"WARNING: DAS maps are stale"}
+	assert.Equal(0, writeResult(&buf, res, Options{Format: "parquet"}))
+	assert.True(bytes.HasPrefix(buf.Bytes(), []byte("PAR1")))
+	assert.True(bytes.HasSuffix(buf.Bytes(), []byte("PAR1")))
+}
+
+// TestWriteParquetStream tests parquet output of many DAS records whose
+// trailing records widen its schema
+func TestWriteParquetStream(t *testing.T) {
+	assert := assert.New(t)
+	das := mongo.DASRecord{"primary_key": "file.name"}
+	var res Result
+	nrecords := 2000
+	for idx := 0; idx < nrecords+10; idx++ {
+		rec := mongo.DASRecord{"das": das, "file": []mongo.DASRecord{{"name": fmt.Sprintf("/store/%d.root", idx), "size": idx}}}
+		if idx >= nrecords+5 {
+			// attributes and values of trailing records widen the schema
+			rec["file"] = []mongo.DASRecord{{"name": fmt.Sprintf("/store/%d.root", idx), "size": "large", "nevents": 1}}
+		}
+		res.Records = append(res.Records, rec)
+	}
+	// duplicate records are written once
+	res.Records = append(res.Records, res.Records[0])
+	res.Unique = true
+	var buf bytes.Buffer
+	nrec, err := writeParquet(&buf, res, Options{Idx: 5})
+	assert.Nil(err)
+	assert.Equal(nrecords+5, nrec)
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(err)
+	assert.Equal(int64(nrecords+5), file.NumRows())
+	rows := parquetRows(t, buf.Bytes())
+	assert.Equal(nrecords+5, len(rows))
+	assert.Equal([]interface{}{map[string]interface{}{"name": "/store/5.root", "size": "5", "nevents": nil}}, rows[0]["file"])
+	last := fmt.Sprintf("/store/%d.root", nrecords+9)
+	assert.Equal([]interface{}{map[string]interface{}{"name": last, "size": "large", "nevents": int64(1)}}, rows[len(rows)-1]["file"])
+}
+
+// TestParquetJSONList tests that attribute which holds both list of values
+// and records is kept in JSON form
+func TestParquetJSONList(t *testing.T) {
+	assert := assert.New(t)
+	res := Result{Records: []mongo.DASRecord{
+		{"run": []mongo.DASRecord{{"events": []int{1, 2}}}},
+		{"run": []mongo.DASRecord{{"events": map[string]interface{}{"a": 1}}}},
+	}}
+	var buf bytes.Buffer
+	nrec, err := writeParquet(&buf, res, Options{})
+	assert.Nil(err)
+	assert.Equal(2, nrec)
+	rows := parquetRows(t, buf.Bytes())
+	assert.Equal([]interface{}{map[string]interface{}{"events": []interface{}{1.0, 2.0}}}, rows[0]["run"])
+	assert.Equal([]interface{}{map[string]interface{}{"events": map[string]interface{}{"a": 1.0}}}, rows[1]["run"])
+}
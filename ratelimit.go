@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenBucket implements token-bucket algorithm with given rate (tokens per
+// second) and burst size
+type TokenBucket struct {
+	Rate   float64
+	Burst  float64
+	tokens float64
+	last   time.Time
+	mutex  sync.Mutex
+}
+
+// NewTokenBucket returns new token bucket filled up to its burst size
+func NewTokenBucket(rate, burst float64) *TokenBucket {
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(rate))
+	}
+	return &TokenBucket{Rate: rate, Burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Reserve takes a token from the bucket and returns how long caller
+// should wait before it can use it
+func (b *TokenBucket) Reserve() time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.Rate
+	if b.tokens > b.Burst {
+		b.tokens = b.Burst
+	}
+	b.last = now
+	b.tokens -= 1
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.Rate * float64(time.Second))
+}
+
+// RateLimiter holds token buckets for individual hosts or CMS data-services
+type RateLimiter struct {
+	Buckets map[string]*TokenBucket
+	waits   map[string]time.Duration
+	calls   map[string]int
+	mutex   sync.Mutex
+}
+
+// global rate limiter, nil means no rate limiting
+var rateLimiter *RateLimiter
+
+// NewRateLimiter creates rate limiter out of given specification, e.g.
+// dbs=10:20,rucio=50,cms-rucio.cern.ch=5:5 where each entry defines
+// requests per second and optional burst size for given CMS data-service
+// or host name, the special key "default" applies to all other URLs
+func NewRateLimiter(spec string) (*RateLimiter, error) {
+	limiter := &RateLimiter{
+		Buckets: make(map[string]*TokenBucket),
+		waits:   make(map[string]time.Duration),
+		calls:   make(map[string]int),
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		arr := strings.SplitN(item, "=", 2)
+		if len(arr) != 2 || arr[0] == "" {
+			return nil, fmt.Errorf("invalid rate limit entry '%s', should be key=rps[:burst]", item)
+		}
+		vals := strings.SplitN(arr[1], ":", 2)
+		rate, err := strconv.ParseFloat(vals[0], 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate '%s' for %s", vals[0], arr[0])
+		}
+		var burst float64
+		if len(vals) == 2 {
+			b, err := strconv.Atoi(vals[1])
+			if err != nil || b <= 0 {
+				return nil, fmt.Errorf("invalid burst '%s' for %s", vals[1], arr[0])
+			}
+			burst = float64(b)
+		}
+		limiter.Buckets[arr[0]] = NewTokenBucket(rate, burst)
+	}
+	return limiter, nil
+}
+
+// helper function to classify url into CMS data-service name, it follows
+// the same convention as das2go utils module but only looks at url host and
+// path, i.e. query parameters never change classification of url
+func urlService(rurl string) string {
+	u, err := url.Parse(rurl)
+	if err != nil {
+		return "combined"
+	}
+	parts := append([]string{u.Hostname()}, strings.Split(strings.Trim(u.Path, "/"), "/")...)
+	for _, part := range parts {
+		for _, srv := range []string{"dbs", "rucio", "reqmgr", "mcm", "conddb", "phedex", "runregistry", "dashboard"} {
+			if strings.Contains(part, srv) {
+				return srv
+			}
+		}
+	}
+	return "combined"
+}
+
+// helper function to find token bucket and its key for given url
+func (l *RateLimiter) bucket(rurl string) (string, *TokenBucket) {
+	if u, err := url.Parse(rurl); err == nil {
+		if b, ok := l.Buckets[u.Hostname()]; ok {
+			return u.Hostname(), b
+		}
+	}
+	srv := urlService(rurl)
+	if b, ok := l.Buckets[srv]; ok {
+		return srv, b
+	}
+	if b, ok := l.Buckets["default"]; ok {
+		return srv, b
+	}
+	return srv, nil
+}
+
+// Wait blocks until given url can be fetched or given context is done and
+// returns time spent in a queue along with context error if any
+func (l *RateLimiter) Wait(ctx context.Context, rurl string) (time.Duration, error) {
+	if l == nil {
+		return 0, ctx.Err()
+	}
+	key, b := l.bucket(rurl)
+	if b == nil {
+		return 0, ctx.Err()
+	}
+	wait := b.Reserve()
+	var err error
+	if wait > 0 {
+		start := time.Now()
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			wait = time.Since(start)
+			err = ctx.Err()
+		}
+	}
+	l.mutex.Lock()
+	l.waits[key] += wait
+	l.calls[key] += 1
+	l.mutex.Unlock()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return wait, err
+}
+
+// Summary returns queue wait time per host/service
+func (l *RateLimiter) Summary() []string {
+	var out []string
+	if l == nil {
+		return out
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var keys []string
+	for k := range l.calls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out = append(out, fmt.Sprintf("%s calls=%d wait=%v", k, l.calls[k], l.waits[k]))
+	}
+	return out
+}
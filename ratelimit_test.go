@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimiter tests parsing of rate limiter specs and bucket look-up
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+
+	limiter, err := NewRateLimiter("dbs=10:2, cms-rucio.cern.ch=5")
+	assert.NoError(err)
+	assert.Equal(2, len(limiter.Buckets))
+	assert.Equal(float64(2), limiter.Buckets["dbs"].Burst)
+	assert.Equal(float64(5), limiter.Buckets["cms-rucio.cern.ch"].Burst)
+
+	key, b := limiter.bucket("https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets")
+	assert.Equal("dbs", key)
+	assert.NotNil(b)
+	key, b = limiter.bucket("http://cms-rucio.cern.ch/replicas")
+	assert.Equal("cms-rucio.cern.ch", key)
+	assert.NotNil(b)
+	_, b = limiter.bucket("https://cmsweb.cern.ch/reqmgr2/data/request")
+	assert.Nil(b)
+
+	for _, spec := range []string{"dbs", "dbs=0", "dbs=1:x", "=1"} {
+		_, err := NewRateLimiter(spec)
+		assert.Error(err, spec)
+	}
+}
+
+// TestURLService tests classification of urls into CMS data-services
+func TestURLService(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("dbs", urlService("https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets"))
+	assert.Equal("rucio", urlService("http://cms-rucio.cern.ch/replicas/cms/?name=/dbs/a/b#c"))
+	assert.Equal("reqmgr", urlService("https://cmsweb.cern.ch/reqmgr2/data/request?dbs=1&rucio=1"))
+	assert.Equal("mcm", urlService("https://cms-pdmv.cern.ch/mcm/public/restapi/requests/get"))
+	assert.Equal("dashboard", urlService("http://dashb-cms-job.cern.ch/dashboard/request.py"))
+	assert.Equal("combined", urlService("https://example.com/api?service=dbs"))
+}
+
+// TestTokenBucket tests that token bucket delays calls beyond its burst size
+func TestTokenBucket(t *testing.T) {
+	assert := assert.New(t)
+	b := NewTokenBucket(10, 2)
+	assert.Equal(time.Duration(0), b.Reserve())
+	assert.Equal(time.Duration(0), b.Reserve())
+	wait := b.Reserve()
+	assert.True(wait > 50*time.Millisecond && wait <= 100*time.Millisecond, wait)
+
+	var limiter *RateLimiter
+	wait, err := limiter.Wait(context.Background(), "https://cmsweb.cern.ch/dbs")
+	assert.Equal(time.Duration(0), wait)
+	assert.NoError(err)
+}
+
+// TestRateLimiterCancel tests that queue wait is interrupted once context
+// of DAS query is done
+func TestRateLimiterCancel(t *testing.T) {
+	assert := assert.New(t)
+	limiter, err := NewRateLimiter("dbs=0.1:1")
+	assert.NoError(err)
+	rurl := "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets"
+	wait, err := limiter.Wait(context.Background(), rurl)
+	assert.Equal(time.Duration(0), wait)
+	assert.NoError(err)
+
+	// next token is available in 10 seconds
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	wait, err = limiter.Wait(ctx, rurl)
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.True(time.Since(start) < time.Second, time.Since(start))
+	assert.True(wait >= 50*time.Millisecond && wait < time.Second, wait)
+	assert.Equal([]string{"dbs calls=2 wait=" + wait.String()}, limiter.Summary())
+}
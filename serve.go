@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+)
+
+// Server represents dasgoclient HTTP server which exposes DAS queries as
+// REST API using credentials of the process owner
+type Server struct {
+	Options    Options       // default DAS query options
+	Timeout    time.Duration // request timeout
+	MaxQueries int           // maximum number of concurrent DAS queries
+	maps       *MapsCache
+	slots      chan struct{}
+}
+
+// NewServer creates new dasgoclient server
+func NewServer(opts Options, maxQueries int, timeout time.Duration) *Server {
+	if maxQueries <= 0 {
+		maxQueries = 1
+	}
+	return &Server{
+		Options:    opts,
+		Timeout:    timeout,
+		MaxQueries: maxQueries,
+		maps:       &MapsCache{Profile: opts.Profile, TTL: 24 * time.Hour},
+		slots:      make(chan struct{}, maxQueries),
+	}
+}
+
+// helper function to get content type of given output format
+func contentType(format string) string {
+	switch strings.ToLower(format) {
+	case "json":
+		return "application/json"
+	case "ndjson":
+		return "application/x-ndjson"
+	case "csv":
+		return "text/csv"
+	}
+	return "text/plain"
+}
+
+// helper function to get HTTP status code of DAS exit code
+func httpStatus(ecode int) int {
+	switch ecode {
+	case 0:
+		return http.StatusOK
+	case utils.DASParserError, utils.DASValidationError, utils.DASQueryError:
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+// helper function to parse query options of HTTP request
+func (s *Server) requestOptions(r *http.Request) (Options, error) {
+	opts := s.Options
+	opts.Format = r.FormValue("format")
+	switch strings.ToLower(opts.Format) {
+	case "", "json", "ndjson", "csv", "table":
+	default:
+		return opts, fmt.Errorf("unsupported format '%s'", opts.Format)
+	}
+	if v := r.FormValue("sep"); v != "" {
+		opts.Sep = v
+	}
+	if v := r.FormValue("unique"); v != "" {
+		opts.Unique = v == "true" || v == "1"
+	}
+	if v := r.FormValue("sort"); v != "" {
+		keys, err := parseSortOption(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid sort value '%s'", v)
+		}
+		opts.Sort = keys
+	}
+	for _, key := range []string{"idx", "limit"} {
+		v := r.FormValue(key)
+		if v == "" {
+			continue
+		}
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 0 {
+			return opts, fmt.Errorf("invalid %s value '%s'", key, v)
+		}
+		if key == "idx" {
+			opts.Idx = val
+		} else {
+			opts.Limit = val
+		}
+	}
+	return opts, nil
+}
+
+// QueryHandler handles /query end-point
+func (s *Server) QueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "only GET method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.FormValue("q")
+	if query == "" {
+		http.Error(w, "please provide DAS query via q parameter", http.StatusBadRequest)
+		return
+	}
+	opts, err := s.requestOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the request timeout covers both waiting for query slot and DAS query
+	// itself, upstream calls of DAS query are cancelled once it expires
+	ctx, cancel := context.WithTimeout(r.Context(), s.Timeout)
+	defer cancel()
+
+	// acquire query slot
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			http.Error(w, "too many concurrent DAS queries", http.StatusServiceUnavailable)
+		}
+		return
+	}
+
+	// run DAS query, the query slot is released once the query goroutine
+	// returns, i.e. timed out queries keep their slot until their upstream
+	// calls are cancelled
+	type response struct {
+		data  []byte
+		ecode int
+	}
+	out := make(chan response, 1)
+	go func() {
+		defer func() { <-s.slots }()
+		time0 := time.Now()
+		var buf bytes.Buffer
+		res := runQuery(ctx, prepareQuery(query), opts, s.maps.Get())
+		ecode := writeResult(&buf, res, opts)
+		dasMetrics.Query(strings.Join(res.Query.Fields, ","), ecode, time.Since(time0))
+		out <- response{data: buf.Bytes(), ecode: ecode}
+	}()
+	select {
+	case resp := <-out:
+		if utils.VERBOSE > 0 {
+			log.Printf("DAS query=%q format=%s ecode=%d", query, opts.Format, resp.ecode)
+		}
+		w.Header().Set("Content-Type", contentType(opts.Format))
+		w.Header().Set("X-Das-Exit-Code", fmt.Sprintf("%d", resp.ecode))
+		w.WriteHeader(httpStatus(resp.ecode))
+		w.Write(resp.data)
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			http.Error(w, "DAS query timeout", http.StatusGatewayTimeout)
+		}
+	}
+}
+
+// helper function to start dasgoclient server on given address
+func serve(listen string, opts Options, maxQueries int, timeout time.Duration) {
+	// query stats and traces are collected per process and they can't
+	// be shared among concurrent DAS queries
+	if queryStats != nil || tracer != nil {
+		log.Println("WARNING: -stats and -otlp options are not supported in serve mode")
+		queryStats = nil
+		tracer = nil
+	}
+	if dasMetrics == nil {
+		dasMetrics = NewMetrics()
+	}
+	sharedClient = &ClientCache{TTL: utils.TLSCertsRenewInterval}
+	server := NewServer(opts, maxQueries, timeout)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/query", server.QueryHandler)
+	mux.Handle("/metrics", dasMetrics)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	log.Printf("dasgoclient server listen on %s, max queries %d, timeout %v", listen, maxQueries, timeout)
+	srv := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/dasql"
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestServerOptions tests parsing of query options of HTTP request
+func TestServerOptions(t *testing.T) {
+	assert := assert.New(t)
+	server := NewServer(Options{Sep: " ", Limit: 10}, 2, time.Second)
+
+	r := httptest.NewRequest("GET", "/query?q=dataset=/a/b/c&format=csv&unique=1&idx=5", nil)
+	opts, err := server.requestOptions(r)
+	assert.Nil(err)
+	assert.Equal("csv", opts.Format)
+	assert.Equal(true, opts.Unique)
+	assert.Equal(5, opts.Idx)
+	assert.Equal(10, opts.Limit)
+
+	r = httptest.NewRequest("GET", "/query?q=dataset=/a/b/c&format=xml", nil)
+	_, err = server.requestOptions(r)
+	assert.NotNil(err)
+	r = httptest.NewRequest("GET", "/query?q=dataset=/a/b/c&limit=-1", nil)
+	_, err = server.requestOptions(r)
+	assert.NotNil(err)
+
+	assert.Equal(http.StatusOK, httpStatus(0))
+	assert.Equal(http.StatusBadRequest, httpStatus(utils.DASParserError))
+	assert.Equal(http.StatusBadGateway, httpStatus(utils.DBSError))
+	assert.Equal("application/x-ndjson", contentType("ndjson"))
+}
+
+// TestServerBadRequest tests rejection of invalid HTTP requests
+func TestServerBadRequest(t *testing.T) {
+	assert := assert.New(t)
+	server := NewServer(Options{}, 1, time.Second)
+
+	w := httptest.NewRecorder()
+	server.QueryHandler(w, httptest.NewRequest("GET", "/query", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	server.QueryHandler(w, httptest.NewRequest("POST", "/query?q=dataset=/a/b/c", nil))
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+}
+
+// TestServerCancel tests that upstream calls of timed out DAS query are
+// cancelled
+func TestServerCancel(t *testing.T) {
+	assert := assert.New(t)
+	var calls, cancelled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+			atomic.AddInt32(&cancelled, 1)
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	dasquery := dasql.DASQuery{Fields: []string{"dataset"}}
+	pkeys := []string{"dataset.name"}
+	for _, urls := range []map[string]string{
+		{server.URL + "/dbs/prod/global/DBSReader/datasets?dataset=/a/b/c": ""},
+		{server.URL + "/dbs/prod/global/DBSReader/datasets?dataset=/a/b/d": "", server.URL + "/dbs/prod/global/DBSReader/datasets?dataset=/a/b/e": ""},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		time0 := time.Now()
+		processURLs(ctx, dasquery, urls, nil, &dasmaps.DASMaps{}, pkeys, nil)
+		assert.True(time.Since(time0) < 5*time.Second)
+		cancel()
+	}
+	assert.Eventually(func() bool { return atomic.LoadInt32(&cancelled) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(int32(3), atomic.LoadInt32(&calls))
+
+	// urls are not fetched when DAS query is already cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	processURLs(ctx, dasquery, map[string]string{server.URL + "/dbs/a": "", server.URL + "/dbs/b": ""}, nil, &dasmaps.DASMaps{}, pkeys, nil)
+	processURLs(ctx, dasquery, map[string]string{server.URL + "/dbs/c": ""}, nil, &dasmaps.DASMaps{}, pkeys, nil)
+	assert.Equal(int32(3), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+	"golang.org/x/term"
+)
+
+// list of DAS filters and aggregators used for tab-completion
+var dasFilters = []string{"grep", "unique", "sort", "count"}
+var dasAggregators = []string{"sum", "count", "min", "max", "avg", "median", "stddev"}
+
+// list of shell meta-commands
+var shellCommands = []string{":json", ":sep", ":unique", ":limit", ":explain", ":history", ":set", ":help", ":quit"}
+
+// maximum number of dataset names we keep for tab-completion
+var shellMaxDatasets = 10000
+
+// Shell represents interactive dasgoclient shell which keeps DAS maps and
+// HTTP client across DAS queries
+type Shell struct {
+	Options  Options
+	maps     *MapsCache
+	keys     []string            // DAS keys
+	datasets map[string]struct{} // dataset names seen in DAS records
+	history  []string            // history of DAS queries
+	hfile    string              // name of history file
+	term     *term.Terminal
+}
+
+// NewShell creates new dasgoclient shell
+func NewShell(opts Options) *Shell {
+	s := &Shell{
+		Options:  opts,
+		maps:     &MapsCache{Profile: opts.Profile, TTL: 24 * time.Hour},
+		datasets: make(map[string]struct{}),
+	}
+	s.keys = s.maps.Get().DASKeys()
+	sort.Strings(s.keys)
+	if home, err := os.UserHomeDir(); err == nil {
+		s.hfile = filepath.Join(home, ".dasgoclient_history")
+		if data, err := os.ReadFile(s.hfile); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					s.history = append(s.history, line)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// helper function to add DAS query to shell history
+func (s *Shell) addHistory(line string) {
+	s.history = append(s.history, line)
+	if s.hfile == "" {
+		return
+	}
+	file, err := os.OpenFile(s.hfile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
+
+// helper function to parse on/off value of meta-command, no value toggles
+// given flag
+func toggle(flag bool, args []string) (bool, error) {
+	if len(args) == 0 {
+		return !flag, nil
+	}
+	switch strings.ToLower(args[0]) {
+	case "on", "true", "1":
+		return true, nil
+	case "off", "false", "0":
+		return false, nil
+	}
+	return flag, fmt.Errorf("invalid value '%s', should be on or off", args[0])
+}
+
+// helper function to print shell settings
+func (s *Shell) settings(w io.Writer) {
+	opts := s.Options
+	fmt.Fprintf(w, "json=%v sep=%q unique=%v limit=%d explain=%v\n",
+		opts.JSON, opts.Sep, opts.Unique, opts.Limit, opts.Explain)
+}
+
+// helper function to print shell help
+func (s *Shell) help(w io.Writer) {
+	fmt.Fprintln(w, "Type DAS query, e.g. dataset=/ZMM*/*/*, or one of the following commands:")
+	fmt.Fprintln(w, "  :json [on|off]     toggle JSON output")
+	fmt.Fprintln(w, "  :sep <separator>   set separator of output values")
+	fmt.Fprintln(w, "  :unique [on|off]   toggle sorted unique output")
+	fmt.Fprintln(w, "  :limit <N>         set limit of output records, 0 means no limit")
+	fmt.Fprintln(w, "  :explain [on|off]  toggle explain mode (show CMS data-services and urls)")
+	fmt.Fprintln(w, "  :history           show history of DAS queries")
+	fmt.Fprintln(w, "  !<N>               run DAS query number N from history")
+	fmt.Fprintln(w, "  :set               show current settings")
+	fmt.Fprintln(w, "  :help              show this help")
+	fmt.Fprintln(w, "  :quit              exit the shell (or use Ctrl-D)")
+	fmt.Fprintln(w, "Use TAB to complete DAS keys, filters, aggregators and dataset names")
+}
+
+// command executes shell meta-command, it returns false if shell should exit
+func (s *Shell) command(w io.Writer, line string) bool {
+	arr := strings.Fields(line)
+	cmd, args := arr[0], arr[1:]
+	var err error
+	switch cmd {
+	case ":quit", ":exit", "quit", "exit":
+		return false
+	case ":json":
+		s.Options.JSON, err = toggle(s.Options.JSON, args)
+	case ":unique":
+		s.Options.Unique, err = toggle(s.Options.Unique, args)
+	case ":explain":
+		s.Options.Explain, err = toggle(s.Options.Explain, args)
+	case ":sep":
+		// separator may contain spaces, e.g. :sep " , "
+		sep := strings.TrimSpace(strings.TrimPrefix(line, cmd))
+		if v, e := strconv.Unquote(sep); e == nil {
+			sep = v
+		}
+		if sep == "" {
+			sep = " "
+		}
+		s.Options.Sep = sep
+	case ":limit":
+		if len(args) == 0 {
+			err = fmt.Errorf("please provide limit value")
+			break
+		}
+		var limit int
+		limit, err = strconv.Atoi(args[0])
+		if err == nil && limit < 0 {
+			err = fmt.Errorf("limit should be non-negative number")
+		}
+		if err == nil {
+			s.Options.Limit = limit
+		}
+	case ":history":
+		for idx, q := range s.history {
+			fmt.Fprintf(w, "%5d  %s\n", idx+1, q)
+		}
+		return true
+	case ":set":
+	case ":help", "help":
+		s.help(w)
+		return true
+	default:
+		err = fmt.Errorf("unknown command %s, use :help to see list of commands", cmd)
+	}
+	if err != nil {
+		fmt.Fprintln(w, "ERROR:", err)
+		return true
+	}
+	s.settings(w)
+	return true
+}
+
+// Query runs DAS query and writes its results to given writer
+func (s *Shell) Query(w io.Writer, query string) int {
+	// query stats and traces are collected per DAS query
+	if queryStats != nil {
+		queryStats = NewQueryStats()
+	}
+	if tracer != nil {
+		tracer = NewTracer(tracer.Endpoint, os.Getenv("TRACEPARENT"))
+	}
+	res := runQuery(context.Background(), prepareQuery(query), s.Options, s.maps.Get())
+	ecode := writeResult(w, res, s.Options)
+	dasMetrics.Query(strings.Join(res.Query.Fields, ","), ecode, time.Since(res.Start))
+	res.span.SetAttr("das.exit_code", ecode)
+	tracer.Flush()
+	// keep dataset names for tab-completion
+	for _, rec := range res.Records {
+		for _, val := range attributeValues(rec, [][]string{attributeKeys("dataset.name")}) {
+			if val != "" && len(s.datasets) < shellMaxDatasets {
+				s.datasets[val] = struct{}{}
+			}
+		}
+	}
+	return ecode
+}
+
+// Execute executes given shell line, it returns false if shell should exit
+func (s *Shell) Execute(w io.Writer, line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	if strings.HasPrefix(line, "!") {
+		idx, err := strconv.Atoi(line[1:])
+		if err != nil || idx < 1 || idx > len(s.history) {
+			fmt.Fprintln(w, "ERROR: no such history entry", line)
+			return true
+		}
+		line = s.history[idx-1]
+		fmt.Fprintln(w, line)
+	}
+	if strings.HasPrefix(line, ":") || line == "help" || line == "quit" || line == "exit" {
+		return s.command(w, line)
+	}
+	s.addHistory(line)
+	if ecode := s.Query(w, line); ecode != 0 && utils.VERBOSE > 0 {
+		fmt.Fprintln(w, "DAS exit code:", ecode, dasExitCodeName(ecode))
+	}
+	return true
+}
+
+// candidates returns list of completion candidates for given word of the line
+func (s *Shell) candidates(prefix, word string) []string {
+	var out []string
+	if strings.HasPrefix(prefix, ":") {
+		return shellCommands
+	}
+	if strings.Contains(prefix, "|") {
+		out = append(out, dasFilters...)
+		for _, agg := range dasAggregators {
+			out = append(out, agg+"(")
+		}
+		return out
+	}
+	if arr := strings.SplitN(word, "=", 2); len(arr) == 2 {
+		switch arr[0] {
+		case "dataset", "parent", "child":
+			for name := range s.datasets {
+				out = append(out, arr[0]+"="+name)
+			}
+			sort.Strings(out)
+		case "system":
+			for _, srv := range s.maps.Get().Services() {
+				out = append(out, "system="+srv)
+			}
+		}
+		return out
+	}
+	for _, key := range s.keys {
+		out = append(out, key)
+	}
+	return out
+}
+
+// helper function to find common prefix of given strings
+func commonPrefix(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	prefix := items[0]
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// Complete implements tab-completion of DAS keys, filters, aggregators
+// and dataset names
+func (s *Shell) Complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	prefix := line[:pos]
+	start := strings.LastIndexAny(prefix, " |(,") + 1
+	word := prefix[start:]
+	var matches []string
+	for _, c := range s.candidates(prefix, word) {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return line, pos, true
+	}
+	completion := commonPrefix(matches)
+	if len(matches) > 1 && completion == word && s.term != nil {
+		fmt.Fprintln(s.term, strings.Join(matches, "  "))
+	}
+	return prefix[:start] + completion + line[pos:], start + len(completion), true
+}
+
+// Run starts read-eval-print loop of the shell
+func (s *Shell) Run() {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		// read DAS queries from stdin, e.g. dasgoclient shell < queries.txt
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !s.Execute(os.Stdout, scanner.Text()) {
+				break
+			}
+		}
+		return
+	}
+	rw := struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}
+	s.term = term.NewTerminal(rw, "das> ")
+	s.term.AutoCompleteCallback = s.Complete
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		s.term.SetSize(width, height)
+	}
+	fmt.Println("dasgoclient shell, type :help for help")
+	for {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			fmt.Println("ERROR: unable to setup terminal", err)
+			return
+		}
+		line, err := s.term.ReadLine()
+		term.Restore(fd, state)
+		if err != nil {
+			// Ctrl-D
+			fmt.Println()
+			return
+		}
+		if !s.Execute(os.Stdout, line) {
+			return
+		}
+	}
+}
+
+// helper function to start dasgoclient shell
+func shell(opts Options) {
+	sharedClient = &ClientCache{TTL: utils.TLSCertsRenewInterval}
+	NewShell(opts).Run()
+}
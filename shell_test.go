@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestShellComplete tests tab-completion of dasgoclient shell
+func TestShellComplete(t *testing.T) {
+	assert := assert.New(t)
+	s := &Shell{
+		keys:     []string{"block", "dataset", "file", "run"},
+		datasets: map[string]struct{}{"/ZMM/Summer11-v1/GEN-SIM": {}, "/ZMM/Summer12-v1/AODSIM": {}},
+	}
+
+	line, pos, ok := s.Complete("fi", 2, '\t')
+	assert.True(ok)
+	assert.Equal("file", line)
+	assert.Equal(4, pos)
+
+	line, _, _ = s.Complete("file data", 9, '\t')
+	assert.Equal("file dataset", line)
+
+	line, _, _ = s.Complete("file dataset=/ZMM/Summer1", 25, '\t')
+	assert.Equal("file dataset=/ZMM/Summer1", line)
+	line, _, _ = s.Complete("file dataset=/ZMM/Summer12", 26, '\t')
+	assert.Equal("file dataset=/ZMM/Summer12-v1/AODSIM", line)
+
+	line, _, _ = s.Complete("file dataset=/a/b/c | gr", 24, '\t')
+	assert.Equal("file dataset=/a/b/c | grep", line)
+	line, _, _ = s.Complete("file dataset=/a/b/c | me", 24, '\t')
+	assert.Equal("file dataset=/a/b/c | median(", line)
+
+	line, _, _ = s.Complete(":exp", 4, '\t')
+	assert.Equal(":explain", line)
+
+	_, _, ok = s.Complete("fi", 2, 'x')
+	assert.False(ok)
+}
+
+// TestShellCommands tests meta-commands of dasgoclient shell
+func TestShellCommands(t *testing.T) {
+	assert := assert.New(t)
+	s := &Shell{Options: Options{Sep: " "}}
+	var buf bytes.Buffer
+
+	assert.True(s.Execute(&buf, ":json"))
+	assert.True(s.Options.JSON)
+	assert.True(s.Execute(&buf, ":json off"))
+	assert.False(s.Options.JSON)
+	assert.True(s.Execute(&buf, ":unique on"))
+	assert.True(s.Options.Unique)
+	assert.True(s.Execute(&buf, ":limit 10"))
+	assert.Equal(10, s.Options.Limit)
+	assert.True(s.Execute(&buf, `:sep ", "`))
+	assert.Equal(", ", s.Options.Sep)
+	assert.True(s.Execute(&buf, ":explain"))
+	assert.True(s.Options.Explain)
+
+	buf.Reset()
+	assert.True(s.Execute(&buf, ":limit -1"))
+	assert.Contains(buf.String(), "ERROR")
+	assert.Equal(10, s.Options.Limit)
+	assert.False(s.Execute(&buf, ":quit"))
+}
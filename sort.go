@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/dmwm/das2go/utils"
+)
+
+// helper function to parse value of -sort option, e.g.
+// file.size:desc,file.name, it uses syntax of sort filter of DAS query pipe
+func parseSortOption(val string) ([]SortKey, error) {
+	if strings.TrimSpace(val) == "" {
+		return nil, nil
+	}
+	pipeline, err := parsePipeline("sort " + val)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipeline.Stages) != 1 || len(pipeline.Aggregators) > 0 {
+		return nil, fmt.Errorf("invalid sort keys %q", val)
+	}
+	return pipeline.Stages[0].Keys, nil
+}
+
+// helper function to sort DAS records by given sort keys, records with
+// equal values keep their order
+func sortRecords(records []mongo.DASRecord, keys []SortKey) []mongo.DASRecord {
+	if len(keys) == 0 {
+		return records
+	}
+	pipeline := Pipeline{Stages: []PipeStage{{Name: "sort", Keys: keys}}}
+	return pipeline.Apply(records)
+}
+
+// helper function to get value of given key of DAS record attribute, e.g.
+// name of file attribute, attribute can be either a record or list of them
+func attributeKeyValue(val interface{}, key string) interface{} {
+	switch v := val.(type) {
+	case mongo.DASRecord:
+		return v[key]
+	case map[string]interface{}:
+		return v[key]
+	case []mongo.DASRecord:
+		for _, item := range v {
+			if kval := item[key]; kval != nil {
+				return kval
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if kval := attributeKeyValue(item, key); kval != nil {
+				return kval
+			}
+		}
+	}
+	return nil
+}
+
+// helper function to get primary key value of DAS record in its original
+// type, e.g. run number for run.run_number primary key
+func primaryKeyValue(rec mongo.DASRecord) interface{} {
+	var pkey string
+	switch das := rec["das"].(type) {
+	case mongo.DASRecord:
+		pkey, _ = das["primary_key"].(string)
+	case map[string]interface{}:
+		pkey, _ = das["primary_key"].(string)
+	}
+	if arr := strings.SplitN(pkey, ".", 2); len(arr) == 2 {
+		if val := attributeKeyValue(rec[arr[0]], arr[1]); val != nil {
+			return val
+		}
+	}
+	// records without primary key are ordered by their content
+	return primaryValue(normalizeRecord(rec))
+}
+
+// helper function to sort DAS records by values of their primary keys,
+// it provides the same order of DAS records regardless of order of
+// responses of CMS data-services, records with the same primary key value,
+// e.g. the same file provided by DBS and Rucio, are ordered by their content
+// without volatile attributes (qhash, das.ts, das.expire)
+func sortByPrimaryKey(records []mongo.DASRecord) []mongo.DASRecord {
+	// defer function profiler
+	defer utils.MeasureTime("dasgoclient/sortByPrimaryKey")
+
+	keys := make([]interface{}, len(records))
+	contents := make([]string, len(records))
+	idx := make([]int, len(records))
+	for i, rec := range records {
+		keys[i] = primaryKeyValue(rec)
+		data, _ := json.Marshal(normalizeRecord(rec))
+		contents[i] = string(data)
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		if cmp := compareValues(keys[idx[i]], keys[idx[j]]); cmp != 0 {
+			return cmp < 0
+		}
+		return contents[idx[i]] < contents[idx[j]]
+	})
+	out := make([]mongo.DASRecord, len(records))
+	for i, j := range idx {
+		out[i] = records[j]
+	}
+	return out
+}
+
+// helper function to compare lines of output by their columns, numeric
+// columns are compared by their values, e.g. run numbers
+func compareLines(a, b, sep string) int {
+	acols, bcols := []string{a}, []string{b}
+	if sep != "" {
+		acols, bcols = strings.Split(a, sep), strings.Split(b, sep)
+	}
+	for idx := 0; idx < len(acols) && idx < len(bcols); idx++ {
+		if cmp := compareValues(acols[idx], bcols[idx]); cmp != 0 {
+			return cmp
+		}
+	}
+	return len(acols) - len(bcols)
+}
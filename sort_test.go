@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to create run DAS record
+func runRecord(run int64) mongo.DASRecord {
+	das := mongo.DASRecord{"primary_key": "run.run_number", "services": []string{"dbs3:runs"}}
+	return mongo.DASRecord{"das": das, "run": []mongo.DASRecord{{"run_number": run}}}
+}
+
+// TestParseSortOption tests parsing of -sort option
+func TestParseSortOption(t *testing.T) {
+	assert := assert.New(t)
+	keys, err := parseSortOption("file.size:desc,file.name, -file.nevents,file.block_name:asc")
+	assert.Nil(err)
+	assert.Equal([]SortKey{
+		{Attr: "file.size", Desc: true},
+		{Attr: "file.name"},
+		{Attr: "file.nevents", Desc: true},
+		{Attr: "file.block_name"},
+	}, keys)
+	keys, err = parseSortOption("")
+	assert.Nil(err)
+	assert.Equal(0, len(keys))
+	for _, val := range []string{",", "file.name,", "file.name | unique", "file.name file.size", "sum(file.size)"} {
+		_, err = parseSortOption(val)
+		assert.NotNil(err, val)
+	}
+}
+
+// TestSortRecords tests sorting of DAS records
+func TestSortRecords(t *testing.T) {
+	assert := assert.New(t)
+	records := []mongo.DASRecord{runRecord(100), runRecord(3), runRecord(20)}
+	var runs []int64
+	for _, rec := range sortByPrimaryKey(records) {
+		runs = append(runs, primaryKeyValue(rec).(int64))
+	}
+	assert.Equal([]int64{3, 20, 100}, runs)
+
+	records = []mongo.DASRecord{
+		fileRecord("/store/b.root", 100, "/a/b/RAW#1"),
+		fileRecord("/store/c.root", 300, "/a/b/RAW#2"),
+		fileRecord("/store/a.root", 300, "/a/b/RAW#1"),
+	}
+	assert.Equal([]string{"/store/a.root", "/store/b.root", "/store/c.root"}, fileNames(sortByPrimaryKey(records)))
+	assert.Equal([]string{"/store/c.root", "/store/a.root", "/store/b.root"}, fileNames(sortRecords(records, []SortKey{{Attr: "file.size", Desc: true}, {Attr: "file.block_name", Desc: true}})))
+	assert.Equal(fileNames(records), fileNames(sortRecords(records, nil)))
+
+	// records without primary key are ordered by their content
+	assert.Equal(`{"block":[{"name":"/a/b/RAW#1"}]}`, primaryKeyValue(mongo.DASRecord{"block": []mongo.DASRecord{{"name": "/a/b/RAW#1"}}}))
+
+	// records with the same primary key are ordered by their content
+	// regardless of order of responses of CMS data-services
+	dbs := fileRecord("/store/a.root", 100, "/a/b/RAW#1")
+	rucio := fileRecord("/store/a.root", 100, "/a/b/RAW#1")
+	rucio["das"] = mongo.DASRecord{"primary_key": "file.name", "services": []string{"rucio:file4dataset"}, "ts": 123, "expire": 456}
+	dbs["das"].(mongo.DASRecord)["ts"] = 789
+	first := sortByPrimaryKey([]mongo.DASRecord{dbs, rucio})
+	second := sortByPrimaryKey([]mongo.DASRecord{rucio, dbs})
+	assert.Equal(first, second)
+
+	// mixed numeric and other values are ordered transitively
+	values := []interface{}{"10a", "9", int64(10), "abc", "NaN", 2.5}
+	sort.SliceStable(values, func(i, j int) bool { return compareValues(values[i], values[j]) < 0 })
+	assert.Equal([]interface{}{2.5, "9", int64(10), "10a", "NaN", "abc"}, values)
+	for _, a := range values {
+		for _, b := range values {
+			for _, c := range values {
+				if compareValues(a, b) < 0 && compareValues(b, c) < 0 {
+					assert.True(compareValues(a, c) < 0, fmt.Sprint(a, b, c))
+				}
+			}
+		}
+	}
+
+	assert.True(compareLines("20 b", "100 a", " ") < 0)
+	assert.True(compareLines("20 b", "20 a", " ") > 0)
+	assert.True(compareLines("20", "20 a", " ") < 0)
+	assert.Equal(0, compareLines("x y", "x y", ""))
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+)
+
+// URLStats represents metrics of individual upstream call
+type URLStats struct {
+	Url       string  `json:"url"`
+	Service   string  `json:"service"`
+	Method    string  `json:"method"`
+	Status    int     `json:"status"`
+	Retries   int     `json:"retries"`
+	Latency   float64 `json:"latency_ms"`
+	Wait      float64 `json:"wait_ms"`
+	RecvBytes int     `json:"bytes"`
+	Records   int     `json:"records"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ServiceStats represents aggregated metrics of CMS data-service
+type ServiceStats struct {
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	Retries   int     `json:"retries"`
+	Latency   float64 `json:"latency_ms"`
+	Wait      float64 `json:"wait_ms"`
+	RecvBytes int     `json:"bytes"`
+	Records   int     `json:"records"`
+}
+
+// QueryStats represents timing and HTTP metrics of DAS query
+type QueryStats struct {
+	URLs        []URLStats              `json:"urls"`
+	Services    map[string]ServiceStats `json:"services"`
+	Fetch       float64                 `json:"fetch_ms"`
+	Wait        float64                 `json:"wait_ms"`
+	Aggregation float64                 `json:"aggregation_ms"`
+	Format      float64                 `json:"format_ms"`
+	Total       float64                 `json:"total_ms"`
+	Records     int                     `json:"records"`
+
+	start    time.Time
+	attempts map[string]int     // number of HTTP calls per request URI
+	statuses map[string]int     // last HTTP status code per request URI
+	waits    map[string]float64 // rate limiter wait time per url
+	mutex    sync.Mutex
+}
+
+// global query stats, nil means stats are not collected
+var queryStats *QueryStats
+
+// NewQueryStats returns new QueryStats object
+func NewQueryStats() *QueryStats {
+	return &QueryStats{
+		Services: make(map[string]ServiceStats),
+		start:    time.Now(),
+		attempts: make(map[string]int),
+		statuses: make(map[string]int),
+		waits:    make(map[string]float64),
+	}
+}
+
+// helper function to convert duration into milliseconds
+func msec(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// helper function to get request URI of given url which we use as a key
+// since DNS cache may substitute host name of the original url
+func requestURI(rurl string) string {
+	rurl = strings.Replace(rurl, "#", "%23", -1)
+	if u, err := url.Parse(rurl); err == nil {
+		return u.RequestURI()
+	}
+	return rurl
+}
+
+// AddAttempt records HTTP call and its status code
+func (s *QueryStats) AddAttempt(rurl string, status int) {
+	if s == nil {
+		return
+	}
+	key := requestURI(rurl)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.attempts[key] += 1
+	s.statuses[key] = status
+}
+
+// AddWait records time url spent in rate limiter queue
+func (s *QueryStats) AddWait(rurl string, wait time.Duration) {
+	if s == nil || wait == 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.waits[requestURI(rurl)] += msec(wait)
+	s.Wait += msec(wait)
+}
+
+// AddResponse records metrics of given url response and number of
+// DAS records we produced out of it
+func (s *QueryStats) AddResponse(r *utils.ResponseType, service string, nrecords int) {
+	if s == nil {
+		return
+	}
+	if service == "" {
+		service = urlService(r.Url)
+	}
+	key := requestURI(r.Url)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rec := URLStats{
+		Url:       r.Url,
+		Service:   service,
+		Method:    r.Method,
+		Status:    s.statuses[key],
+		Latency:   msec(r.Time),
+		Wait:      s.waits[key],
+		RecvBytes: r.RecvBytes,
+		Records:   nrecords,
+	}
+	if n := s.attempts[key]; n > 1 {
+		rec.Retries = n - 1
+	}
+	if r.Error != nil {
+		rec.Error = r.Error.Error()
+	}
+	s.URLs = append(s.URLs, rec)
+	srv := s.Services[service]
+	srv.Calls += 1
+	if rec.Error != "" || rec.Status >= 400 {
+		srv.Errors += 1
+	}
+	srv.Retries += rec.Retries
+	srv.Latency += rec.Latency
+	srv.Wait += rec.Wait
+	srv.RecvBytes += rec.RecvBytes
+	srv.Records += rec.Records
+	s.Services[service] = srv
+}
+
+// AddLocalApi records metrics of DAS local API call
+func (s *QueryStats) AddLocalApi(api, service string, elapsed time.Duration, nrecords int) {
+	if s == nil {
+		return
+	}
+	r := utils.ResponseType{Url: fmt.Sprintf("local:%s", api), Method: "LOCAL", Time: elapsed}
+	s.AddResponse(&r, service, nrecords)
+}
+
+// Measure returns function which adds elapsed time to given stage of
+// DAS query processing (fetch, aggregation or format), e.g.
+// defer queryStats.Measure("aggregation")()
+func (s *QueryStats) Measure(stage string) func() {
+	start := time.Now()
+	return func() {
+		if s == nil {
+			return
+		}
+		elapsed := msec(time.Since(start))
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		switch stage {
+		case "fetch":
+			s.Fetch += elapsed
+		case "aggregation":
+			s.Aggregation += elapsed
+		case "format":
+			s.Format += elapsed
+		}
+	}
+}
+
+// Finalize sets total time of DAS query and number of produced records
+func (s *QueryStats) Finalize(nrecords int) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Records = nrecords
+	s.Total = msec(time.Since(s.start))
+	sort.SliceStable(s.URLs, func(i, j int) bool {
+		return s.URLs[i].Latency > s.URLs[j].Latency
+	})
+}
+
+// JSON returns JSON representation of query stats
+func (s *QueryStats) JSON() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("{\"error\":%q}", err.Error())
+	}
+	return string(data)
+}
+
+// Print writes human readable stats report to given writer
+func (s *QueryStats) Print(w io.Writer) {
+	if s == nil {
+		return
+	}
+	fmt.Fprintln(w, "### DAS query stats")
+	for _, r := range s.URLs {
+		fmt.Fprintf(w, "%s %s status=%d retries=%d latency=%.1fms wait=%.1fms bytes=%d records=%d",
+			r.Service, r.Url, r.Status, r.Retries, r.Latency, r.Wait, r.RecvBytes, r.Records)
+		if r.Error != "" {
+			fmt.Fprintf(w, " error=%q", r.Error)
+		}
+		fmt.Fprintln(w)
+	}
+	var srvs []string
+	for k := range s.Services {
+		srvs = append(srvs, k)
+	}
+	sort.Strings(srvs)
+	for _, k := range srvs {
+		v := s.Services[k]
+		fmt.Fprintf(w, "service %s: calls=%d errors=%d retries=%d latency=%.1fms wait=%.1fms bytes=%d records=%d\n",
+			k, v.Calls, v.Errors, v.Retries, v.Latency, v.Wait, v.RecvBytes, v.Records)
+	}
+	fmt.Fprintf(w, "fetch=%.1fms wait=%.1fms aggregation=%.1fms format=%.1fms total=%.1fms records=%d\n",
+		s.Fetch, s.Wait, s.Aggregation, s.Format, s.Total, s.Records)
+}
+
+// statsTransport is HTTP transport which records status code of every
+// HTTP call (including retries) in query stats
+type statsTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	queryStats.AddAttempt(req.URL.String(), status)
+	return resp, err
+}
+
+// helper function to wrap HTTP client transport with stats transport
+func statsClient(client *http.Client) *http.Client {
+	if queryStats == nil {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &statsTransport{base: base}
+	return client
+}
+
+// helper function to write query stats either into das_client JSON wrapper
+// or to stderr to keep output of DAS records intact
+func writeStats(w io.Writer, nrecords int, wrapper bool) {
+	if queryStats == nil {
+		return
+	}
+	queryStats.Finalize(nrecords)
+	if wrapper {
+		fmt.Fprintf(w, ", \"stats\":%s", queryStats.JSON())
+		return
+	}
+	queryStats.Print(os.Stderr)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestQueryStats tests collection of per-url and per-service metrics
+func TestQueryStats(t *testing.T) {
+	assert := assert.New(t)
+	stats := NewQueryStats()
+
+	rurl := "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/files?block_name=/a/b/c#123"
+	stats.AddWait(rurl, 10*time.Millisecond)
+	stats.AddAttempt("https://1.2.3.4/dbs/prod/global/DBSReader/files?block_name=/a/b/c%23123", 502)
+	stats.AddAttempt("https://1.2.3.4/dbs/prod/global/DBSReader/files?block_name=/a/b/c%23123", 200)
+	r := utils.ResponseType{Url: "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/files?block_name=/a/b/c%23123", Method: "GET", Time: 20 * time.Millisecond, RecvBytes: 100}
+	stats.AddResponse(&r, "dbs3", 5)
+	r = utils.ResponseType{Url: "https://cms-rucio.cern.ch/replicas", Method: "GET", Time: 30 * time.Millisecond, Error: errors.New("timeout")}
+	stats.AddResponse(&r, "", 0)
+	stats.Finalize(5)
+
+	assert.Equal(2, len(stats.URLs))
+	assert.Equal("rucio", stats.URLs[0].Service)
+	assert.Equal("timeout", stats.URLs[0].Error)
+	dbs := stats.URLs[1]
+	assert.Equal(200, dbs.Status)
+	assert.Equal(1, dbs.Retries)
+	assert.Equal(float64(10), dbs.Wait)
+	assert.Equal(float64(20), dbs.Latency)
+	assert.Equal(5, stats.Services["dbs3"].Records)
+	assert.Equal(1, stats.Services["rucio"].Errors)
+
+	// rate limiter queue wait time is part of the timing line
+	assert.Equal(float64(10), stats.Wait)
+	var buf bytes.Buffer
+	stats.Print(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.True(strings.HasPrefix(lines[len(lines)-1], "fetch=0.0ms wait=10.0ms aggregation="), lines[len(lines)-1])
+
+	var empty *QueryStats
+	empty.AddResponse(&r, "", 0)
+	empty.Measure("fetch")()
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// minimal width of column with truncated LFNs
+const minLFNWidth = 24
+
+// helper function to get width of terminal of given writer, it returns zero
+// if writer is not a terminal
+func terminalWidth(w io.Writer) int {
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil {
+		return width
+	}
+	return 0
+}
+
+// helper function to check if attribute holds size in bytes, e.g. file.size
+func sizeAttribute(attr string) bool {
+	return strings.HasSuffix(attr, ".size") || strings.HasSuffix(attr, "_size")
+}
+
+// helper function to check if attribute holds Unix timestamp, e.g.
+// dataset.creation_date
+func timeAttribute(attr string) bool {
+	return strings.HasSuffix(attr, "_date") || strings.HasSuffix(attr, "_time")
+}
+
+// helper function to check if attribute holds LFN
+func lfnAttribute(attr string) bool {
+	return attr == "file.name" || strings.HasSuffix(attr, "lfn")
+}
+
+// helper function to convert value of given attribute into human readable
+// form, values which are not numbers, e.g. joined lists, are kept intact
+func humanValue(attr, val string) string {
+	if _, ok := numericValue(val); !ok {
+		return val
+	}
+	switch {
+	case sizeAttribute(attr):
+		return humanSize(val)
+	case timeAttribute(attr):
+		return formatTimestamp(val)
+	}
+	return val
+}
+
+// helper function to truncate LFN to given width, the file name is kept
+// whenever possible, e.g. /store/data/.../file.root
+func truncateLFN(lfn string, width int) string {
+	if utf8.RuneCountInString(lfn) <= width || len(lfn) != utf8.RuneCountInString(lfn) {
+		return lfn
+	}
+	if width <= 3 {
+		return lfn[:width]
+	}
+	tail := lfn[strings.LastIndex(lfn, "/")+1:]
+	if len(tail)+4 > width {
+		// file name itself is too long, keep its beginning and end
+		tail = lfn[len(lfn)-(width-3)/2:]
+	} else {
+		tail = "/" + tail
+	}
+	return lfn[:width-3-len(tail)] + "..." + tail
+}
+
+// helper function to write DAS records as table with aligned columns,
+// sizes and timestamps are shown in human readable form, LFNs are truncated
+// to fit terminal width (if width is positive) and totals are shown at the
+// bottom, it returns number of written rows
+func writeTable(w io.Writer, res Result, opts Options, width int) int {
+	header, rows := tableRows(res, opts)
+	rows = pageRows(rows, opts.Idx, opts.Limit)
+
+	// attribute of every column of given row, results of aggregators have
+	// attribute in key column, e.g. sum of file.size is size in bytes
+	funcIdx, keyIdx := -1, -1
+	if len(res.Query.Aggregators) > 0 {
+		for idx, name := range header {
+			switch name {
+			case "function":
+				funcIdx = idx
+			case "key":
+				keyIdx = idx
+			}
+		}
+	}
+	attribute := func(row []string, idx int) string {
+		if header[idx] == "value" && funcIdx >= 0 && keyIdx >= 0 && row[funcIdx] != "count" {
+			return row[keyIdx]
+		}
+		return header[idx]
+	}
+
+	// convert values into human readable form and accumulate totals
+	var totalSize float64
+	sizeIdx := -1
+	for idx, name := range header {
+		if sizeAttribute(name) && sizeIdx < 0 {
+			sizeIdx = idx
+		}
+	}
+	table := make([][]string, len(rows))
+	for i, row := range rows {
+		table[i] = make([]string, len(row))
+		for j, val := range row {
+			table[i][j] = humanValue(attribute(row, j), val)
+		}
+		if sizeIdx >= 0 {
+			if size, ok := numericValue(row[sizeIdx]); ok {
+				totalSize += size
+			}
+		}
+	}
+
+	// find width of columns and truncate LFNs if table is wider than terminal
+	widths := make([]int, len(header))
+	numeric := make([]bool, len(header))
+	for j, name := range header {
+		widths[j] = utf8.RuneCountInString(name)
+		numeric[j] = len(rows) > 0
+		for i, row := range table {
+			widths[j] = max(widths[j], utf8.RuneCountInString(row[j]))
+			if _, ok := numericValue(rows[i][j]); !ok && rows[i][j] != missingValue {
+				numeric[j] = false
+			}
+		}
+	}
+	total := 2 * (len(header) - 1)
+	for _, n := range widths {
+		total += n
+	}
+	if width > 0 && total > width {
+		for j, name := range header {
+			if !lfnAttribute(name) || total <= width {
+				continue
+			}
+			n := max(widths[j]-(total-width), minLFNWidth, utf8.RuneCountInString(name))
+			if n >= widths[j] {
+				continue
+			}
+			for _, row := range table {
+				row[j] = truncateLFN(row[j], n)
+			}
+			total -= widths[j] - n
+			widths[j] = n
+		}
+	}
+
+	// write table, numeric columns are aligned to the right
+	writeRow := func(row []string) {
+		cells := make([]string, len(row))
+		for j, val := range row {
+			pad := strings.Repeat(" ", widths[j]-utf8.RuneCountInString(val))
+			if numeric[j] {
+				cells[j] = pad + val
+			} else {
+				cells[j] = val + pad
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	writeRow(header)
+	lines := make([]string, len(header))
+	for j := range header {
+		lines[j] = strings.Repeat("-", widths[j])
+	}
+	writeRow(lines)
+	for _, row := range table {
+		writeRow(row)
+	}
+	summary := fmt.Sprintf("%d records", len(rows))
+	if len(rows) == 1 {
+		summary = "1 record"
+	}
+	if sizeIdx >= 0 {
+		summary += fmt.Sprintf(", total %s %s", header[sizeIdx], humanSize(totalSize))
+	}
+	fmt.Fprintln(w, summary)
+	return len(rows)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTruncateLFN tests truncation of LFNs
+func TestTruncateLFN(t *testing.T) {
+	assert := assert.New(t)
+	lfn := "/store/data/Run2018A/DoubleMuon/RAW/v1/000/316/469/00000/ACEDE0D3.root"
+	assert.Equal(lfn, truncateLFN(lfn, len(lfn)))
+	assert.Equal("/store/data/Run.../ACEDE0D3.root", truncateLFN(lfn, 32))
+	assert.Equal(32, len(truncateLFN(lfn, 32)))
+	assert.Equal("/st.../ACEDE0D3.root", truncateLFN(lfn, 20))
+	assert.Equal("/st", truncateLFN(lfn, 3))
+
+	assert.Equal("2.1 GB", humanValue("file.size", "2063019163"))
+	assert.Equal("100,200", humanValue("file.size", "100,200"))
+	assert.Equal("2011-12-30 05:00:08", humanValue("dataset.creation_date", "1325221208.0"))
+	assert.Equal("N/A", humanValue("block.creation_time", "N/A"))
+	assert.Equal("1325221208", humanValue("file.nevents", "1325221208"))
+}
+
+// TestWriteTable tests table output of DAS records
+func TestWriteTable(t *testing.T) {
+	assert := assert.New(t)
+	dasquery, _, err, _ := parseDASQuery("file dataset=/a/b/c | grep file.name, file.size", "", []string{"file", "dataset"})
+	assert.Equal("", err)
+	res := Result{Query: dasquery, Records: []mongo.DASRecord{
+		fileRecord("/store/data/Run2018A/RAW/v1/000/316/469/00000/ACEDE0D3.root", 2063019163, "/a/b/c#1"),
+		fileRecord("/store/b.root", 100, "/a/b/c#1"),
+	}}
+	var buf bytes.Buffer
+	nrec := writeTable(&buf, res, Options{}, 0)
+	assert.Equal(2, nrec)
+	expect := `file.name                                                    file.size
+-----------------------------------------------------------  ---------
+/store/data/Run2018A/RAW/v1/000/316/469/00000/ACEDE0D3.root     2.1 GB
+/store/b.root                                                    100 B
+2 records, total file.size 2.1 GB
+`
+	assert.Equal(expect, buf.String())
+
+	buf.Reset()
+	writeTable(&buf, res, Options{Limit: 1}, 40)
+	expect = `file.name                      file.size
+-----------------------------  ---------
+/store/data/.../ACEDE0D3.root     2.1 GB
+1 record, total file.size 2.1 GB
+`
+	assert.Equal(expect, buf.String())
+
+	dasquery = parsedQuery(t, "file dataset=/a/b/c | sum(file.size), count")
+	res = Result{Query: dasquery, Records: aggregateRecords(res.Records, dasquery.Aggregators, nil)}
+	buf.Reset()
+	writeTable(&buf, res, Options{}, 0)
+	expect = `function  key         value
+--------  ---------  ------
+sum       file.size  2.1 GB
+count     file            2
+2 records
+`
+	assert.Equal(expect, buf.String())
+}
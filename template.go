@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+)
+
+// layout of timestamps of DAS records
+const timeLayout = "2006-01-02 15:04:05"
+
+// templateFuncs represents helper functions of -template option
+var templateFuncs = template.FuncMap{
+	"human":   humanSize,
+	"date":    formatTimestamp,
+	"datefmt": formatTime,
+	"pfn":     pfn,
+	"lumis":   lumiRanges,
+}
+
+// helper function to convert size in bytes into human readable form,
+// e.g. 2063019163 into 2.1 GB
+func humanSize(val interface{}) string {
+	size, ok := numericValue(val)
+	if !ok {
+		return missingValue
+	}
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+	idx := 0
+	for math.Abs(size) >= 1000 && idx < len(units)-1 {
+		size /= 1000
+		idx += 1
+	}
+	if idx == 0 {
+		return fmt.Sprintf("%d %s", int64(size), units[idx])
+	}
+	return fmt.Sprintf("%.1f %s", size, units[idx])
+}
+
+// helper function to convert Unix timestamp of DAS record, e.g.
+// creation_date, into time in given layout
+func formatTime(layout string, val interface{}) string {
+	sec, ok := numericValue(val)
+	if !ok {
+		return missingValue
+	}
+	return timeOf(sec).UTC().Format(layout)
+}
+
+// helper function to convert Unix timestamp in seconds into time
+func timeOf(sec float64) time.Time {
+	whole := math.Floor(sec)
+	return time.Unix(int64(whole), int64((sec-whole)*1e9))
+}
+
+// helper function to convert Unix timestamp of DAS record into UTC time
+func formatTimestamp(val interface{}) string {
+	return formatTime(timeLayout, val)
+}
+
+// helper function to convert LFN into PFN by given prefix, e.g.
+// root://cms-xrd-global.cern.ch/ prefix yields
+// root://cms-xrd-global.cern.ch//store/... file
+func pfn(prefix string, lfn interface{}) string {
+	if lfn == nil {
+		return missingValue
+	}
+	return prefix + formatValue(lfn)
+}
+
+// helper function to collect integer numbers of DAS record value
+func integerValues(val interface{}, out []int64) []int64 {
+	if items, ok := val.([]interface{}); ok {
+		for _, item := range items {
+			out = integerValues(item, out)
+		}
+		return out
+	}
+	if num, ok := numericValue(val); ok {
+		out = append(out, int64(num))
+	}
+	return out
+}
+
+// helper function to compact lumi numbers into lumi ranges in a form of
+// CMS lumi mask, e.g. [1,2,3,7] into [[1,3],[7,7]]
+func lumiRanges(val interface{}) string {
+	lumis := integerValues(val, nil)
+	if len(lumis) == 0 {
+		return missingValue
+	}
+	sort.Slice(lumis, func(i, j int) bool { return lumis[i] < lumis[j] })
+	var ranges [][]int64
+	for _, lumi := range lumis {
+		if n := len(ranges); n > 0 && lumi <= ranges[n-1][1]+1 {
+			if lumi > ranges[n-1][1] {
+				ranges[n-1][1] = lumi
+			}
+			continue
+		}
+		ranges = append(ranges, []int64{lumi, lumi})
+	}
+	data, _ := json.Marshal(ranges)
+	return string(data)
+}
+
+// helper function to parse template of -template option
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("template").Funcs(templateFuncs).Parse(text)
+}
+
+// helper function to convert generic DAS record into data of template, lists
+// of sub-records, e.g. file records of different CMS data-services, are
+// merged into single record to allow {{.file.name}} expressions
+func templateRecord(rec map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for key, val := range rec {
+		items, ok := val.([]interface{})
+		if !ok {
+			out[key] = val
+			continue
+		}
+		merged := make(map[string]interface{})
+		for _, item := range items {
+			sub, ok := item.(map[string]interface{})
+			if !ok {
+				merged = nil
+				break
+			}
+			for k, v := range sub {
+				if merged[k] == nil {
+					merged[k] = v
+				}
+			}
+		}
+		if merged == nil {
+			out[key] = val
+		} else {
+			out[key] = merged
+		}
+	}
+	return out
+}
+
+// helper function to write DAS records using template of -template option,
+// it returns number of written records and error of template execution
+func writeTemplate(w io.Writer, res Result, opts Options) (int, error) {
+	tmpl, err := parseTemplate(opts.Template)
+	if err != nil {
+		return 0, err
+	}
+	var records []string
+	for _, rec := range res.Records {
+		if recordError(rec) != nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, templateRecord(genericRecord(rec))); err != nil {
+			return 0, err
+		}
+		// attributes missing in DAS record are shown as N/A
+		out := strings.ReplaceAll(buf.String(), "<no value>", missingValue)
+		records = append(records, strings.TrimSuffix(out, "\n"))
+	}
+	if res.Unique {
+		records = utils.List2Set(records)
+	}
+	var nrec int
+	for idx, rec := range records {
+		if idx < opts.Idx {
+			continue
+		}
+		if opts.Limit > 0 && nrec == opts.Limit {
+			break
+		}
+		fmt.Fprintln(w, rec)
+		nrec += 1
+	}
+	return nrec, nil
+}
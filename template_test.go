@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dmwm/das2go/mongo"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTemplateFuncs tests helper functions of -template option
+func TestTemplateFuncs(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("100 B", humanSize(json.Number("100")))
+	assert.Equal("2.1 GB", humanSize(json.Number("2063019163")))
+	assert.Equal("1.5 TB", humanSize(1.5e12))
+	assert.Equal("N/A", humanSize(nil))
+	assert.Equal("2011-12-30 05:00:08", formatTimestamp(json.Number("1325221208.0")))
+	assert.Equal("2011-12-30", formatTime("2006-01-02", int64(1325221208)))
+	assert.Equal("root://cms-xrd-global.cern.ch//store/a.root", pfn("root://cms-xrd-global.cern.ch/", "/store/a.root"))
+	assert.Equal("[[1,3],[7,7],[9,10]]", lumiRanges([]interface{}{json.Number("9"), json.Number("2"), json.Number("1"), json.Number("3"), json.Number("7"), json.Number("10"), json.Number("2")}))
+	assert.Equal("[[5,5]]", lumiRanges(json.Number("5")))
+	assert.Equal("N/A", lumiRanges(nil))
+}
+
+// TestWriteTemplate tests template output of DAS records
+func TestWriteTemplate(t *testing.T) {
+	assert := assert.New(t)
+	res := Result{Records: []mongo.DASRecord{
+		fileRecord("/store/a.root", 2063019163, "/a/b/RAW#1"),
+		fileRecord("/store/b.root", 100, "/a/b/RAW#1", "/a/b/RAW#2"),
+	}}
+	var buf bytes.Buffer
+	opts := Options{Template: `{{.file.name | pfn "root://xrootd/"}} {{.file.size | human}} {{.file.nevents}}`}
+	nrec, err := writeTemplate(&buf, res, opts)
+	assert.Nil(err)
+	assert.Equal(2, nrec)
+	assert.Equal("root://xrootd//store/a.root 2.1 GB N/A\nroot://xrootd//store/b.root 100 B N/A\n", buf.String())
+
+	buf.Reset()
+	opts = Options{Template: "{{.das.primary_key}}\n", Limit: 1}
+	res.Unique = true
+	nrec, err = writeTemplate(&buf, res, opts)
+	assert.Nil(err)
+	assert.Equal(1, nrec)
+	assert.Equal("file.name\n", buf.String())
+
+	_, err = parseTemplate("{{.file.name")
+	assert.NotNil(err)
+	_, err = writeTemplate(&buf, res, Options{Template: "{{.file.name | bogus}}"})
+	assert.NotNil(err)
+}
@@ -0,0 +1,104 @@
+{"urn": "dataset4site_release", "url": "combined plugin", "expire": 10800, "params": {"release": "required", "site": "required"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "site", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "combined", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "dataset4site_release_parent", "url": "combined plugin", "expire": 10800, "params": {"release": "required", "site": "required", "parent": "required"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "site", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}, {"das_key": "parent", "rec_key": "parent.name", "api_arg": "parent", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "combined", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "child4site_release_dataset", "url": "combined plugin", "expire": 10800, "params": {"release": "required", "site": "required", "parent": "required"}, "lookup": "child", "das_map": [{"das_key": "child", "rec_key": "child.name"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "site", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "parent", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "combined", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "site4dataset_pct", "url": "combined plugin", "expire": 10800, "params": {"dataset": "required"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name", "pattern": "^T[0-3]_"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "combined", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "site4dataset", "url": "combined plugin", "expire": 10800, "params": {"dataset": "required"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name", "pattern": "^T[0-3]_"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "combined", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "site4block", "url": "combined plugin", "expire": 10800, "params": {"block": "required"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name", "pattern": "^T[0-3]_"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "combined", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "files4dataset_runs_site", "url": "combined plugin", "format": "JSON", "expire": 10800, "params": {"dataset": "required", "run": "required", "site": "required"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "site", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "combined", "type": "service", "hash": "x", "instances": null}
+{"urn": "files4block_runs_site", "url": "combined plugin", "format": "JSON", "expire": 10800, "params": {"block": "required", "run": "required", "site": "required"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "site", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "combined", "type": "service", "hash": "x", "instances": null}
+{"urn": "get_run_info", "url": "https://cms-conddb.cern.ch/getLumi/", "expire": 3600, "params": {"Runs": "", "date": "optional"}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "Runs"}, {"das_key": "date", "rec_key": "date", "api_arg": "date"}], "system": "conddb", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"system": "conddb", "notations": [{"api_output": "Run-Info", "rec_key": "run_info", "api": ""}, {"api_output": "Run", "rec_key": "run_number", "api": "get_run_info"}, {"api_output": "Run", "rec_key": "run.run_number", "api": "get_lumi_info"}, {"api_output": "DeliveredLumi", "rec_key": "delivered_lumi", "api": ""}, {"api_output": "Lumi", "rec_key": "lumi", "api": ""}, {"api_output": "lumisection", "rec_key": "number", "api": ""}], "type": "notation", "hash": "x"}
+{"urn": "site_names", "url": "local_api", "expire": 3600, "params": {"preset": "site-names", "rcsite_state": "ANY", "json": 1, "match": "optional"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name", "api_arg": "match", "pattern": "^T[0-3]"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "match", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "cric", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "groups", "url": "local_api", "expire": 3600, "params": {"preset": "group-responsibilities", "json": 1, "match": "optional"}, "lookup": "group", "das_map": [{"das_key": "group", "rec_key": "group.name", "api_arg": "match"}], "system": "cric", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "group_responsibilities", "url": "local_api", "expire": 3600, "params": {"preset": "group-responsibilities", "json": 1, "match": "optional"}, "lookup": "group", "das_map": [{"das_key": "group", "rec_key": "group.name", "api_arg": "match"}], "system": "cric", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "people_via_email", "url": "local_api", "expire": 3600, "params": {"preset": "people", "json": 1, "match": "optional"}, "lookup": "user", "das_map": [{"das_key": "user", "rec_key": "user.email", "api_arg": "match", "pattern": "[a-zA-Z0-9]+@[a-zA-Z0-9]+\\.[a-zA-Z]{2,4}"}], "system": "cric", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "people_via_name", "url": "local_api", "expire": 3600, "params": {"preset": "people", "json": 1, "match": "optional"}, "lookup": "user", "das_map": [{"das_key": "user", "rec_key": "user.name", "api_arg": "match"}], "system": "cric", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "roles", "url": "local_api", "expire": 3600, "params": {"preset": "roles", "json": 1, "match": "optional"}, "lookup": "role", "das_map": [{"das_key": "role", "rec_key": "user.role", "api_arg": "match"}], "system": "cric", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"system": "cric", "notations": [{"api_output": "site.cms_name", "rec_key": "site.name", "api": ""}, {"api_output": "user_group", "rec_key": "name", "api": "group_responsibilities"}, {"api_output": "alias", "rec_key": "name", "api": ""}], "type": "notation", "hash": "x"}
+{"urn": "jobsummary-plot-or-table", "url": "http://dashb-cms-job.cern.ch/dashboard/request.py/jobsummary-plot-or-table2", "expire": 300, "params": {"user": "", "site": "", "ce": "", "submissiontool": "", "dataset": "", "application": "", "rb": "", "activity": "", "grid": "", "date1": "", "date2": "", "date": "optional", "jobtype": "", "tier": "", "check": "submitted"}, "lookup": "jobsummary", "das_map": [{"das_key": "jobsummary", "rec_key": "jobsummary.name", "api_arg": ""}, {"das_key": "site", "rec_key": "site.se", "api_arg": "ce", "pattern": "([a-zA-Z0-9]+\\.){2}"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]"}, {"das_key": "user", "rec_key": "user.name", "api_arg": "user"}, {"das_key": "date", "rec_key": "date", "api_arg": "date"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "application"}], "system": "dashboard", "format": "XML", "type": "service", "hash": "x", "instances": null}
+{"system": "dashboard", "notations": [{"api_output": "bField", "rec_key": "bfield", "api": ""}, {"api_output": "hltKey", "rec_key": "hlt", "api": ""}, {"api_output": "runNumber", "rec_key": "run_number", "api": ""}], "type": "notation", "hash": "x"}
+{"urn": "acquisitioneras", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/acquisitioneras/", "expire": 900, "params": {"era": "optional"}, "lookup": "era", "das_map": [{"das_key": "era", "rec_key": "era.name", "api_arg": "era"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datatypes", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datatypes/", "expire": 900, "params": {"dataset": "optional"}, "lookup": "datatype", "das_map": [{"das_key": "datatype", "rec_key": "datatype.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datatiers", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datatiers", "expire": 900, "params": {}, "lookup": "tier", "das_map": [{"das_key": "tier", "rec_key": "tier.name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datasetaccesstypes", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasetaccesstypes", "expire": 900, "params": {}, "lookup": "status", "das_map": [{"das_key": "status", "rec_key": "status.name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "releaseversions", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/releaseversions/", "expire": 900, "params": {"release_version": "optional", "dataset": "optional", "logical_file_name": "optional"}, "lookup": "release", "das_map": [{"das_key": "release", "rec_key": "release.name", "api_arg": "release_version"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "primarydatasets", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/primarydatasets/", "expire": 900, "params": {"primary_ds_name": "*"}, "lookup": "primary_dataset", "das_map": [{"das_key": "primary_dataset", "rec_key": "primary_dataset.name", "api_arg": "primary_ds_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "dataset4block", "url": "local_api", "expire": 86400, "params": {"block_name": "required"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "dataset4parent_release", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets/", "expire": 86400, "params": {"parent_dataset": "required", "release_version": "required", "detail": "True"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "parent", "rec_key": "parent.name", "api_arg": "parent_dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release_version"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datasets", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets/", "expire": 900, "params": {"dataset": "optional", "primary_ds_name": "optional", "primary_ds_type": "optional", "processed_ds_name": "optional", "detail": "True", "dataset_access_type": "VALID", "data_tier_name": "optional", "release_version": "optional", "run_num": "optional", "logical_file_name": "optional", "acquisition_era_name": "optional", "physics_group_name": "optional", "cdate": "optional", "create_by": "optional", "prep_id": "optional"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/.*/.*/.*"}, {"das_key": "primary_dataset", "rec_key": "primary_dataset.name", "api_arg": "primary_ds_name"}, {"das_key": "datatype", "rec_key": "datatype.name", "api_arg": "primary_ds_type"}, {"das_key": "tier", "rec_key": "tier.name", "api_arg": "data_tier_name", "pattern": ".*[A-Z].*"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release_version"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}, {"das_key": "era", "rec_key": "era", "api_arg": "acquisition_era_name"}, {"das_key": "group", "rec_key": "group.name", "api_arg": "physics_group_name"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "dataset_access_type"}, {"das_key": "date", "rec_key": "date", "api_arg": "cdate"}, {"das_key": "user", "rec_key": "user.name", "api_arg": "create_by"}, {"das_key": "prepid", "rec_key": "prepid", "api_arg": "prep_id"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datasetlist", "url": "local_api", "expire": 900, "params": {"dataset": "required", "detail": "True", "dataset_access_type": "VALID"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "\\[\"/[\\w-]+/[\\w-]+/[A-Z-]+\"(\\,\\s*\"/[\\w-]+/[\\w-]+/[A-Z-]+)+\"\\]"}, {"das_key": "primary_dataset", "rec_key": "primary_dataset.name", "api_arg": "primary_ds_name"}, {"das_key": "datatype", "rec_key": "datatype.name", "api_arg": "primary_ds_type"}, {"das_key": "tier", "rec_key": "tier.name", "api_arg": "data_tier_name", "pattern": ".*[A-Z].*"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release_version"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}, {"das_key": "era", "rec_key": "era", "api_arg": "acquisition_era_name"}, {"das_key": "group", "rec_key": "group.name", "api_arg": "physics_group_name"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "dataset_access_type"}, {"das_key": "date", "rec_key": "date", "api_arg": "cdate"}, {"das_key": "user", "rec_key": "user.name", "api_arg": "create_by"}, {"das_key": "prepid", "rec_key": "prepid", "api_arg": "prep_id"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "dataset_info", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets/", "expire": 900, "params": {"dataset": "*", "detail": "True", "dataset_access_type": "*"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "filesummaries", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filesummaries/", "expire": 900, "params": {"dataset": "required", "validFileOnly": "1"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "summary4dataset_run", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filesummaries/", "expire": 900, "params": {"dataset": "required", "run_num": "optional", "validFileOnly": "1"}, "lookup": "summary", "das_map": [{"das_key": "summary", "rec_key": "summary"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "summary4block_run", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filesummaries/", "expire": 900, "params": {"run_num": "optional", "block_name": "required"}, "lookup": "summary", "das_map": [{"das_key": "summary", "rec_key": "summary"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datasetparents", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasetparents/", "expire": 900, "params": {"dataset": "required"}, "lookup": "parent", "das_map": [{"das_key": "parent", "rec_key": "parent.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "datasetchildren", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasetchildren/", "expire": 900, "params": {"dataset": "required"}, "lookup": "child", "das_map": [{"das_key": "child", "rec_key": "child.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "outputconfigs", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/outputconfigs/", "expire": 900, "params": {"dataset": "required"}, "lookup": "config", "das_map": [{"das_key": "config", "rec_key": "config.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "site4dataset", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blocks/", "expire": 900, "params": {"detail": "True", "dataset": "required", "dataset_access_type": "optional"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "site4block", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blocks/", "expire": 900, "params": {"detail": "True", "block_name": "required", "dataset_access_type": "optional"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "blocks", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blocks/", "expire": 900, "params": {"block_name": "optional", "detail": "True", "dataset": "optional", "dataset_access_type": "optional", "run_num": "optional", "logical_file_name": "optional"}, "lookup": "block", "das_map": [{"das_key": "block", "rec_key": "block.name", "api_arg": "block_name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "blockorigin", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blockorigin/", "expire": 900, "params": {"origin_site_name": "required", "dataset": "required"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "origin_site_name", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "blockparents", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blockparents/", "expire": 900, "params": {"block_name": "required"}, "lookup": "parent", "das_map": [{"das_key": "parent", "rec_key": "parent.name"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "blockchildren", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/blockchildren/", "expire": 900, "params": {"block_name": "required"}, "lookup": "child", "das_map": [{"das_key": "child", "rec_key": "child.name"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "files", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/files/", "expire": 900, "params": {"logical_file_name": "required", "detail": "True", "status": "optional"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "status"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "files_via_dataset", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/files/", "expire": 900, "params": {"dataset": "required", "detail": "True", "release_version": "optional", "status": "optional"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release_version"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "status"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "files_via_block", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/files/", "expire": 900, "params": {"block_name": "required", "detail": "True", "run_num": "optional", "release_version": "optional", "status": "optional"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "release", "rec_key": "release.name", "api_arg": "release_version"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "status"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "fileparents", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/fileparents/", "expire": 900, "params": {"logical_file_name": "required"}, "lookup": "parent", "das_map": [{"das_key": "parent", "rec_key": "parent.name"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "filechildren", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filechildren/", "expire": 900, "params": {"logical_file_name": "required"}, "lookup": "child", "das_map": [{"das_key": "child", "rec_key": "child.name"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "filelumis", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis/", "expire": 900, "params": {"logical_file_name": "required", "run_num": "optional"}, "lookup": "lumi", "das_map": [{"das_key": "lumi", "rec_key": "lumi.number"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file4DatasetRunLumi", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/files/", "expire": 900, "params": {"dataset": "required", "run_num": "required", "detail": "True", "status": "optional"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi_list"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "status"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "runs", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/runs/", "expire": 900, "params": {"run_num": "required"}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "runsummaries", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/runsummaries/", "expire": 900, "params": {"run_num": "required", "dataset": "optional"}, "lookup": "lumi", "das_map": [{"das_key": "lumi", "rec_key": "lumi.number"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "runs_via_dataset", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/runs/", "expire": 900, "params": {"dataset": "required"}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "runs_via_block", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/runs/", "expire": 900, "params": {"block_name": "required"}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "runs_via_file", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/runs/", "expire": 900, "params": {"logical_file_name": "required"}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number"}, {"das_key": "file", "rec_key": "file.name", "api_arg": "logical_file_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "physicsgroup", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/physicsgroups/", "expire": 900, "params": {"physics_group_name": "optional"}, "lookup": "group", "das_map": [{"das_key": "group", "rec_key": "group.name", "api_arg": "physics_group_name"}], "system": "dbs3", "format": "JSON", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "lumi4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional"}, "lookup": "lumi", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "lumi4block", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional"}, "lookup": "lumi", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "run_lumi4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional"}, "lookup": "run,lumi", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "run_lumi4block", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional"}, "lookup": "run,lumi", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_lumi4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,lumi", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_lumi4block", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional", "ValidFileOnly": "optional"}, "lookup": "file,lumi", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_run4dataset", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,run", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_run4block", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,run", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_run_lumi4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,run,lumi", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_run_lumi4block", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,run,lumi", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "block_run_lumi4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional"}, "lookup": "block,run,lumi", "das_map": [{"das_key": "block", "rec_key": "block.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "lumi4block_run", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional"}, "lookup": "lumi", "das_map": [{"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "run_lumi_evts4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional"}, "lookup": "run,lumi,events", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "run_lumi_evts4block", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional"}, "lookup": "run,lumi,events", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_lumi_evts4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,lumi,events", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_lumi_evts4block", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional", "ValidFileOnly": "optional"}, "lookup": "file,lumi,events", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_run_lumi_evts4dataset", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,run,lumi,events", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file_run_lumi_evts4block", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional", "validFileOnly": "optional"}, "lookup": "file,run,lumi,events", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "block_run_lumi_evts4dataset", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "optional"}, "lookup": "block,run,lumi,events", "das_map": [{"das_key": "block", "rec_key": "block.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "lumi_evts4block_run", "url": "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/filelumis", "format": "JSON", "expire": 3600, "params": {"block_name": "required", "run_num": "optional"}, "lookup": "lumi,events", "das_map": [{"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "events", "rec_key": "events.number", "api_arg": "events"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block_name", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+#[0-9a-zA-Z-]"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "file4dataset_run_lumi", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"dataset": "required", "run_num": "required", "lumi": "required", "validFileOnly": "optional"}, "lookup": "file", "das_map": [{"das_key": "file", "rec_key": "file.name"}, {"das_key": "run", "rec_key": "run.run_number", "api_arg": "run_num", "pattern": "^\\d+$|.*\\[\\s*\\d+\\s*[,\\s*\\d+\\s*]*\\].*|{.*\\d+.*\\d+}"}, {"das_key": "lumi", "rec_key": "lumi.number", "api_arg": "lumi"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "status", "rec_key": "status.name", "api_arg": "validFileOnly"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"urn": "blocks4tier_dates", "url": "local_api", "format": "JSON", "expire": 3600, "params": {"tier": "required", "date": "required"}, "lookup": "block", "das_map": [{"das_key": "block", "rec_key": "block.name"}, {"das_key": "tier", "rec_key": "tier.name", "api_arg": "tier", "pattern": ".*[A-Z].*"}, {"das_key": "date", "rec_key": "date", "api_arg": "date"}], "system": "dbs3", "type": "service", "hash": "x", "instances": ["prod/global", "prod/phys01", "prod/phys02", "prod/phys03", "prod/caf", "int/global", "int/phys01", "int/phys02", "int/phys03", "dev/global", "dev/phys01", "dev/phys02", "dev/phys03"]}
+{"system": "dbs3", "notations": [{"api_output": "dataset", "rec_key": "name", "api": "blockorigin"}, {"api_output": "creation_date", "rec_key": "creation_time", "api": ""}, {"api_output": "last_modification_date", "rec_key": "modification_time", "api": ""}, {"api_output": "create_by", "rec_key": "created_by", "api": ""}, {"api_output": "last_modified_by", "rec_key": "modified_by", "api": ""}, {"api_output": "primary_ds_name", "rec_key": "name", "api": "primarydatasets"}, {"api_output": "primary_ds_name", "rec_key": "primary_dataset.name", "api": ""}, {"api_output": "primary_ds_type", "rec_key": "datatype", "api": ""}, {"api_output": "primary_ds_id", "rec_key": "id", "api": "primarydatasets"}, {"api_output": "primary_ds_id", "rec_key": "primary_dataset.id", "api": ""}, {"api_output": "child_dataset", "rec_key": "name", "api": ""}, {"api_output": "parent_dataset", "rec_key": "name", "api": ""}, {"api_output": "parent_block_name", "rec_key": "name", "api": ""}, {"api_output": "child_logical_file_name", "rec_key": "name", "api": ""}, {"api_output": "parent_logical_file_name", "rec_key": "name", "api": ""}, {"api_output": "block_name", "rec_key": "name", "api": "blocks"}, {"api_output": "block_name", "rec_key": "name", "api": "blockchildren"}, {"api_output": "block_size", "rec_key": "size", "api": "blocks"}, {"api_output": "block_name", "rec_key": "block.name", "api": ""}, {"api_output": "block_size", "rec_key": "block.size", "api": ""}, {"api_output": "num_block", "rec_key": "nblocks", "api": ""}, {"api_output": "num_event", "rec_key": "nevents", "api": ""}, {"api_output": "logical_file_name", "rec_key": "name", "api": "files"}, {"api_output": "logical_file_name", "rec_key": "name", "api": "files_via_dataset"}, {"api_output": "logical_file_name", "rec_key": "name", "api": "files_via_block"}, {"api_output": "logical_file_name", "rec_key": "file", "api": "filelumis"}, {"api_output": "logical_file_name", "rec_key": "file", "api": "filelumis4block"}, {"api_output": "logical_file_name", "rec_key": "name", "api": "file4DatasetRunLumi"}, {"api_output": "file.logical_file_name", "rec_key": "file.name", "api": ""}, {"api_output": "file_type", "rec_key": "type", "api": "files"}, {"api_output": "file_type", "rec_key": "type", "api": "files_via_dataset"}, {"api_output": "file_type", "rec_key": "type", "api": "files_via_block"}, {"api_output": "file_type", "rec_key": "type", "api": "file4DatasetRunLumi"}, {"api_output": "file.file_type", "rec_key": "file.type", "api": ""}, {"api_output": "file.block_name", "rec_key": "file.block.name", "api": ""}, {"api_output": "file_size", "rec_key": "size", "api": "files"}, {"api_output": "file_size", "rec_key": "size", "api": "filesummaries"}, {"api_output": "file_size", "rec_key": "size", "api": "files_via_dataset"}, {"api_output": "file_size", "rec_key": "size", "api": "files_via_block"}, {"api_output": "file_size", "rec_key": "size", "api": "file4DatasetRunLumi"}, {"api_output": "file.file_size", "rec_key": "file.size", "api": ""}, {"api_output": "event_count", "rec_key": "nevents", "api": "files"}, {"api_output": "event_count", "rec_key": "nevents", "api": "files_via_dataset"}, {"api_output": "event_count", "rec_key": "nevents", "api": "files_via_block"}, {"api_output": "event_count", "rec_key": "nevents", "api": "file4DatasetRunLumi"}, {"api_output": "num_event", "rec_key": "nevents", "api": "filesummaries"}, {"api_output": "num_block", "rec_key": "nblocks", "api": "filesummaries"}, {"api_output": "file.event_count", "rec_key": "file.nevents", "api": ""}, {"api_output": "file_count", "rec_key": "nfiles", "api": ""}, {"api_output": "run_num", "rec_key": "run_number", "api": "runs"}, {"api_output": "run_num", "rec_key": "run_number", "api": "runs_via_dataset"}, {"api_output": "run_num", "rec_key": "run_number", "api": "runs_via_block"}, {"api_output": "run_num", "rec_key": "run_number", "api": "runs_via_file"}, {"api_output": "run_num", "rec_key": "run_number", "api": "filelumis"}, {"api_output": "run_num", "rec_key": "run_number", "api": "filelumis4block"}, {"api_output": "run_num", "rec_key": "run.run_number", "api": ""}, {"api_output": "num_file", "rec_key": "nfiles", "api": ""}, {"api_output": "num_lumi", "rec_key": "nlumis", "api": ""}, {"api_output": "event_count", "rec_key": "nevents", "api": ""}, {"api_output": "lumi_section_num", "rec_key": "number", "api": ""}, {"api_output": "data_type", "rec_key": "name", "api": ""}, {"api_output": "output_module_label", "rec_key": "module_label", "api": ""}, {"api_output": "acquisition_era_name", "rec_key": "name", "api": "acquisitioneras"}, {"api_output": "physics_group_name", "rec_key": "name", "api": "physicsgroups"}, {"api_output": "release_version", "rec_key": "name", "api": "releaseversions"}, {"api_output": "app_name", "rec_key": "name", "api": "outputconfigs"}, {"api_output": "dataset_access_type", "rec_key": "name", "api": "datasetaccesstypes"}, {"api_output": "dataset_access_type", "rec_key": "status", "api": ""}, {"api_output": "data_tier_name", "rec_key": "name", "api": "datatiers"}], "type": "notation", "hash": "x"}
+{"urn": "mcm_summary", "url": "https://cms-pdmv.cern.ch/mcm/public/restapi/requests/get", "expire": 600, "params": {"prepid": "required"}, "lookup": "mcm", "das_map": [{"das_key": "mcm", "rec_key": "mcm.prepid"}, {"das_key": "prepid", "rec_key": "prepid", "api_arg": "prepid"}], "system": "mcm", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "mcm4dataset", "url": "https://cms-pdmv.cern.ch/mcm/public/restapi/requests/produces", "expire": 600, "params": {"dataset": "required"}, "lookup": "mcm", "das_map": [{"das_key": "mcm", "rec_key": "mcm.prepid"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "mcm", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "dataset4mcm", "url": "https://cms-pdmv.cern.ch/mcm/public/restapi/requests/output", "expire": 600, "params": {"prepid": "required"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name"}, {"das_key": "prepid", "rec_key": "prepid", "api_arg": "prepid"}], "system": "mcm", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"system": "mcm", "notations": [{"api_output": "results", "rec_key": "mcm", "api": ""}, {"api_output": "total_events", "rec_key": "nevents", "api": ""}, {"api_output": "cmssw_release", "rec_key": "release", "api": ""}, {"api_output": "reqmgr_name", "rec_key": "reqmgr", "api": ""}], "type": "notation", "hash": "x"}
+{"urn": "configs", "url": "local_api", "expire": 900, "params": {"dataset": "required"}, "lookup": "config", "das_map": [{"das_key": "config", "rec_key": "config.name"}, {"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}], "system": "reqmgr2", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "datasetByPrepID", "url": "https://cmsweb.cern.ch/reqmgr2/data/request", "expire": 900, "params": {"prep_id": "required"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset", "pattern": "/[\\w-]+/[\\w-]+/[A-Z-]+"}, {"das_key": "prepid", "rec_key": "prepid", "api_arg": "prep_id"}], "system": "reqmgr2", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"system": "reqmgr2", "notations": [{"api_output": "primary_ds_name", "rec_key": "name", "api": "primarydatasets"}], "type": "notation", "hash": "x"}
+{"urn": "dataset4site", "url": "http://cms-rucio.cern.ch/replicas/rse", "expire": 3600, "params": {"se": "optional", "node": "optional"}, "lookup": "dataset", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "node", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "se", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "block4site", "url": "http://cms-rucio.cern.ch/replicas/rse", "expire": 3600, "params": {"se": "optional", "node": "optional"}, "lookup": "block", "das_map": [{"das_key": "block", "rec_key": "block.name", "api_arg": "block"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "node", "pattern": "^T[0-3]_"}, {"das_key": "site", "rec_key": "site.se", "api_arg": "se", "pattern": "([a-zA-Z0-9-_]+\\.){2}"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "block4dataset", "url": "http://cms-rucio.cern.ch/dids/cms/", "expire": 3600, "params": {"dataset": "required"}, "lookup": "block", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "rules4dataset", "url": "http://cms-rucio.cern.ch/dids/cms/", "expire": 3600, "params": {"dataset": "required"}, "lookup": "rules", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "rules", "rec_key": "rules.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "rules4block", "url": "http://cms-rucio.cern.ch/dids/cms/", "expire": 3600, "params": {"block": "required"}, "lookup": "rules", "das_map": [{"das_key": "block", "rec_key": "block.name", "api_arg": "block"}, {"das_key": "rules", "rec_key": "rules.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "rules4file", "url": "http://cms-rucio.cern.ch/dids/cms/", "expire": 3600, "params": {"file": "required"}, "lookup": "rules", "das_map": [{"das_key": "file", "rec_key": "file.name", "api_arg": "file"}, {"das_key": "rules", "rec_key": "rules.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "site4block", "url": "http://cms-rucio.cern.ch/replicas/cms", "expire": 3600, "params": {"block": "required"}, "lookup": "site", "das_map": [{"das_key": "block", "rec_key": "block.name", "api_arg": "block"}, {"das_key": "site", "rec_key": "site.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "site4file", "url": "http://cms-rucio.cern.ch/replicas/cms", "expire": 3600, "params": {"file": "required"}, "lookup": "site", "das_map": [{"das_key": "file", "rec_key": "file.name", "api_arg": "file"}, {"das_key": "site", "rec_key": "site.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "file4dataset", "url": "http://cms-rucio.cern.ch/replicas/cms", "expire": 3600, "params": {"dataset": "required"}, "lookup": "file", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "file", "rec_key": "file.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "file4dataset_site", "url": "http://cms-rucio.cern.ch/replicas/cms", "expire": 3600, "params": {"dataset": "required", "site": "required"}, "lookup": "file", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "file", "rec_key": "file.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "file4block_site", "url": "http://cms-rucio.cern.ch/replicas/cms", "expire": 3600, "params": {"block": "required", "site": "required"}, "lookup": "file", "das_map": [{"das_key": "block", "rec_key": "block.name", "api_arg": "block"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "file", "rec_key": "file.name", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "block4dataset_site", "url": "http://cms-rucio.cern.ch/replicas/cms", "expire": 3600, "params": {"dataset": "required", "site": "required"}, "lookup": "block", "das_map": [{"das_key": "dataset", "rec_key": "dataset.name", "api_arg": "dataset"}, {"das_key": "site", "rec_key": "site.name", "api_arg": "site", "pattern": "^T[0-3]_"}, {"das_key": "block", "rec_key": "block.name", "api_arg": "block"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "rses", "url": "http://cms-rucio.cern.ch/rses", "expire": 3600, "params": {"node": "optional"}, "lookup": "site", "das_map": [{"das_key": "site", "rec_key": "site.name", "api_arg": "node", "pattern": "^T[0-3]_"}], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "accounts", "url": "http://cms-rucio.cern.ch/accounts", "expire": 3600, "params": {}, "lookup": "user", "das_map": [], "system": "rucio", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"system": "rucio", "notations": [{"api_output": "time_create", "rec_key": "creation_time", "api": ""}, {"api_output": "time_update", "rec_key": "modification_time", "api": ""}, {"api_output": "bytes", "rec_key": "size", "api": ""}, {"api_output": "node", "rec_key": "site", "api": ""}, {"api_output": "node", "rec_key": "node", "api": "nodeusage"}, {"api_output": "files", "rec_key": "nfiles", "api": ""}, {"api_output": "events", "rec_key": "nevents", "api": ""}, {"api_output": "lfn", "rec_key": "name", "api": ""}], "type": "notation", "hash": "x"}
+{"urn": "rr_xmlrpc", "url": "http://runregistry.web.cern.ch/runregistry/", "expire": 3600, "params": {"run": ""}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run"}], "system": "runregistry", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"urn": "rr_xmlrpc2", "url": "http://runregistry.web.cern.ch/runregistry/", "expire": 3600, "params": {"run": "", "date": ""}, "lookup": "run", "das_map": [{"das_key": "run", "rec_key": "run.run_number", "api_arg": "run"}, {"das_key": "date", "rec_key": "date"}], "system": "runregistry", "format": "JSON", "type": "service", "hash": "x", "instances": null}
+{"system": "runregistry", "notations": [{"api_output": "bField", "rec_key": "bfield", "api": ""}, {"api_output": "hltKey", "rec_key": "hlt", "api": ""}, {"api_output": "runNumber", "rec_key": "run_number", "api": ""}, {"api_output": "events", "rec_key": "nevents", "api": ""}, {"api_output": "lumi_sections", "rec_key": "nlumis", "api": ""}, {"api_output": "create_time", "rec_key": "creation_time", "api": ""}, {"api_output": "modify_time", "rec_key": "modification_time", "api": ""}], "type": "notation", "hash": "x"}
+{"presentation": {"primary_dataset": [{"das": "primary_dataset.name", "ui": "Primary dataset", "link": [{"name": "Datasets", "query": "dataset primary_dataset=%s"}], "description": "is a name of primary dataset defined in DBS system", "examples": ["primary_dataset=Cosmics"]}, {"das": "primary_dataset.type", "ui": "Type"}, {"das": "primary_dataset.create_by", "ui": "Created by:"}], "rules": [{"das": "rules.name", "ui": "Rucio rules", "link": [], "description": "is a rule on DID defined by Rucio", "examples": ["rules dataset=/a/b/c", "rules block=/a/b/c#123"]}, {"das": "rules.rse_expression", "ui": "RSE expression"}, {"das": "rules.copies", "ui": "Number of copies"}, {"das": "rules.split_container", "ui": "Split dataset"}, {"das": "rules.comments", "ui": "Comments"}, {"das": "rules.state", "ui": "State"}, {"das": "rules.expires_at", "ui": "Expires"}, {"das": "rules.account", "ui": "Rucio Account"}], "summary": [{"das": "summary", "ui": "Summary", "diff": ["summary.nlumis", "summary.file_size", "summary.nevents", "summary.nblocks", "summary.nfiles"], "link": [], "description": "is a DAS keyword to get summary information for certain queries, right now it is only used for summary information about dataset/run pairs and include information about number of files/lumis/blocks/events and file size", "examples": ["summary dataset=/a/b/c run=123"]}, {"das": "summary.nfiles", "ui": "Number of files"}, {"das": "summary.nlumis", "ui": "Number of lumis"}, {"das": "summary.nblocks", "ui": "Number of blocks"}, {"das": "summary.nevents", "ui": "Number of events"}, {"das": "summary.file_size", "ui": "sum(file_size)"}, {"das": "summary.run", "ui": "Run"}, {"das": "summary.file", "ui": "File"}, {"das": "summary.dataset", "ui": "Dataset"}, {"das": "summary.era", "ui": "era"}, {"das": "summary.tier", "ui": "Data tier"}, {"das": "summary.custodial", "ui": "Custodial"}, {"das": "summary.size", "ui": "Size"}, {"das": "summary.release", "ui": "CMSSW release"}, {"das": "summary.notes", "ui": "Physics channel"}, {"das": "summary.pwg", "ui": "Physics group"}, {"das": "nfiles", "ui": "Number of files"}, {"das": "nlumis", "ui": "Number of lumis"}, {"das": "nblocks", "ui": "Number of blocks"}, {"das": "nevents", "ui": "Number of events"}, {"das": "file_size", "ui": "sum(file_size)"}, {"das": "run", "ui": "Run"}, {"das": "file", "ui": "File"}, {"das": "dataset", "ui": "Dataset"}, {"das": "era", "ui": "era"}, {"das": "tier", "ui": "Data tier"}, {"das": "custodial", "ui": "Custodial"}, {"das": "size", "ui": "Size"}], "dataset": [{"das": "dataset.name", "ui": "Dataset", "diff": ["dataset.datatype", "dataset.status", "dataset.size", "dataset.nevents", "dataset.nblocks", "dataset.nfiles"], "link": [{"name": "Release", "query": "release dataset=%s"}, {"name": "Blocks", "query": "block dataset=%s"}, {"name": "Files", "query": "file dataset=%s"}, {"name": "Runs", "query": "run dataset=%s"}, {"name": "Configs", "query": "config dataset=%s"}, {"name": "Parents", "query": "parent dataset=%s"}, {"name": "Children", "query": "child dataset=%s"}, {"name": "Sites", "query": "site dataset=%s"}, {"name": "Physics Groups", "query": "group dataset=%s"}], "description": "is a name of CMS dataset which represented as a path /primary_dataset/processed_dataset/data_tier", "examples": ["dataset=/ZMM*/*/*"]}, {"das": "dataset.mcm.prepid", "ui": "McM info", "link": [{"name": "McM", "query": "mcm prepid=%s"}], "description": "PrepID info provided by McM data-service", "examples": ["mcm prepid=HIG-Summer12-01312"]}, {"das": "dataset.nfiles", "ui": "Number of files"}, {"das": "dataset.nblocks", "ui": "Number of blocks"}, {"das": "dataset.nevents", "ui": "Number of events"}, {"das": "dataset.size", "ui": "Dataset size"}, {"das": "dataset.datatype", "ui": "Type"}, {"das": "dataset.status", "ui": "Status"}, {"das": "dataset.custodial", "ui": "Custodial"}, {"das": "dataset.creation_time", "ui": "Creation time"}, {"das": "dataset.primary_datatset_name", "ui": "Primary dataset"}, {"das": "dataset.processed_datatset_name", "ui": "Processed dataset"}, {"das": "dataset.primary_dataset_name", "ui": "Primary dataset"}, {"das": "dataset.processed_dataset_name", "ui": "Processed dataset"}, {"das": "dataset.physics_group_name", "ui": "Physics group"}, {"das": "dataset.global_tag", "ui": "Tag"}, {"das": "dataset.xtcrosssection", "ui": "Cross section"}, {"das": "dataset.release_version", "ui": "Release"}, {"das": "dataset.error", "ui": "Error"}, {"das": "dataset.httperror", "ui": "Reason"}], "block": [{"das": "block.name", "ui": "Block name", "diff": ["block.size", "block.nevents", "block.nfiles"], "link": [{"name": "Dataset", "query": "dataset block=%s"}, {"name": "Files", "query": "file block=%s"}, {"name": "Runs", "query": "run block=%s"}, {"name": "Parents", "query": "parent block=%s"}, {"name": "Sites", "query": "site block=%s"}], "description": "is a name used by DBS and Phedex systems to refer set of files usually associated with data transfer. It consists of dataset path followed by unique block id, e.g. /prim_dataset/proc_dataset/tier#123", "examples": ["block=/a/b/c#123"]}, {"das": "block.size", "ui": "Block size"}, {"das": "block.nevents", "ui": "Number of events"}, {"das": "block.nfiles", "ui": "Number of files"}, {"das": "block.is_open", "ui": "Open"}, {"das": "block.replica.site", "ui": "Site"}, {"das": "block.error", "ui": "Error"}, {"das": "block.httperror", "ui": "Reason"}], "file": [{"das": "file.name", "ui": "File name", "diff": ["file.size", "file.nevents"], "link": [{"name": "Dataset", "query": "dataset file=%s"}, {"name": "Block", "query": "block file=%s"}, {"name": "Sites", "query": "site file=%s"}, {"name": "Runs", "query": "run file=%s"}, {"name": "Parents", "query": "parent file=%s"}, {"name": "Children", "query": "child file=%s"}, {"name": "Lumis", "query": "lumi file=%s"}], "description": "is a logical file name used in DBS/Phedex systems", "examples": ["file=/lfn.root"]}, {"das": "file.size", "ui": "File size"}, {"das": "file.status", "ui": "Status"}, {"das": "file.type", "ui": "File type"}, {"das": "file.nevents", "ui": "Number of events"}, {"das": "file.replica.site", "ui": "Site"}, {"das": "file.error", "ui": "Error"}, {"das": "file.httperror", "ui": "Reason"}], "run": [{"das": "run.run_number", "ui": "Run number", "diff": ["run.nevents"], "link": [{"name": "Datasets", "query": "dataset run=%s"}], "description": "is run number (usually six digits) used by DBS/RunRegistry/CondDB/Tier-0 systems", "examples": ["run=160915"]}, {"das": "run.nevents", "ui": "Number of events"}, {"das": "run.nlumis", "ui": "Number of lumis"}, {"das": "run.global_tag", "ui": "Global tag"}, {"das": "run.lhcFill", "ui": "LHC fill"}, {"das": "run.bfield", "ui": "Magnetic Field"}, {"das": "run.delivered_lumi", "ui": "Delivered lumi"}, {"das": "run.duration", "ui": "Duration"}, {"das": "run.error", "ui": "Error"}, {"das": "run.httperror", "ui": "Reason"}], "run_status": [{"das": "run_status", "ui": "Run status", "link": [], "description": "is a value used by Tier-0 system for run identification", "examples": ["run run_status=Complete", "run run_status=CloseOut"]}, {"das": "run.run_number", "ui": "Run number"}, {"das": "run.start_time", "ui": "Start time"}], "stream": [{"das": "stream", "ui": "Stream name", "link": [], "description": "is a value used by Tier-0 system for run identification", "examples": ["run stream=Express"]}, {"das": "run.run_number", "ui": "Run number"}, {"das": "run.start_time", "ui": "Start time"}], "lumi": [{"das": "lumi.number", "ui": "Luminosity ranges", "link": [], "description": "is used to identify luminosity block during run data-taking, it is provided by DBS/CondDB/LumiDB systems", "examples": ["lumi file=/lfn.root", "lumi file=/lfn.root run=160915", "lumi block=/a/b/c#123", "lumi block=/a/b/c#123 | count(lumi)"]}, {"das": "lumi.delivered", "ui": "Delivered luminosiy"}, {"das": "lumi.integrated", "ui": "Integrated luminosiy"}, {"das": "lumi.run_number", "ui": "Run number"}, {"das": "lumi.start_event", "ui": "Start event"}, {"das": "lumi.end_event", "ui": "End event"}], "events": [{"das": "events.number", "ui": "Events", "link": [], "description": "number of events either in a given lumi or file"}], "tier": [{"das": "tier.name", "ui": "Tier name", "link": [{"name": "Datasets", "query": "dataset tier=%s"}], "description": "is a data tier used by DBS system to identify dataset meaning, there are different pre-defined data-tiers, such as GEN, SIM, RECO, ALCORECO, RAW and mixed one, e.g. GEN-SIM-RECO", "examples": ["dataset tier=*GEN*"]}, {"das": "tier.error", "ui": "Error"}, {"das": "tier.httperror", "ui": "Reason"}], "release": [{"das": "release.name", "ui": "Release name", "link": [{"name": "Datasets", "query": "dataset release=%s"}], "description": "is a name of CMSSW release, e.g. CMSSW_6_0_1", "examples": ["release=CMSSW_6_0_1"]}, {"das": "release.algorithm.executable", "ui": "Executable"}, {"das": "release.algorithm.name", "ui": "Algorithm name"}, {"das": "release.error", "ui": "Error"}, {"das": "release.httperror", "ui": "Reason"}], "site": [{"das": "site.name", "ui": "Site name", "link": [{"name": "Datasets", "query": "dataset site=%s"}], "description": "is CMS site name used by Phedex and SiteDB systems", "examples": ["site=T3_US_Cornell"]}, {"das": "site.se", "ui": "StorageElement", "link": [{"name": "Site", "query": "site=%s"}], "description": "is a name of storage element used by Phedex/SiteDB systems", "examples": ["site=osg-se.cac.cornell.edu"]}, {"das": "site.block_fraction", "ui": "Block presence"}, {"das": "site.block_completion", "ui": "Block completion"}, {"das": "site.replica_fraction", "ui": "File-replica presence"}, {"das": "site.kind", "ui": "Site type"}, {"das": "site.admin.email", "ui": "Admin Email(s)"}, {"das": "site.info.usage", "ui": "Usage"}, {"das": "site.node.name", "ui": "Node name"}, {"das": "site.node.cust_node_files", "ui": "Custodial files"}, {"das": "site.node.cust_node_bytes", "ui": "Custodial size"}, {"das": "site.node.nocust_node_files", "ui": "Non-custodial files"}, {"das": "site.node.nocust_node_bytes", "ui": "Non-custtoial size"}, {"das": "site.resources.fqdn", "ui": "CE/SE's"}, {"das": "site.error", "ui": "Error"}, {"das": "site.httperror", "ui": "HTTP error"}, {"das": "error", "ui": "Error"}, {"das": "reason", "ui": "Reason"}], "monitor": [{"das": "monitor", "ui": "Monitor", "link": [], "description": "is a DAS keyword to find monitoring information about phedex for specific period of time", "examples": ["monitor date last 24h"]}, {"das": "monitor.node", "ui": "Phedex Node"}, {"das": "monitor.rate", "ui": "Transfer rate"}, {"das": "monitor.country", "ui": "Country"}, {"das": "monitor.region", "ui": "Region"}, {"das": "monitor.time", "ui": "Time interval"}], "group": [{"das": "group.name", "ui": "Group name", "link": [], "description": "is a CMS group name, e.g. Higgs, it can be used to identify CMS datasets or SiteDB groups", "examples": ["dataset group=Top", "group=DataOps"]}, {"das": "group.username", "ui": "Member(s)"}, {"das": "group.node_files", "ui": "# of files archived on this node"}, {"das": "group.dest_files", "ui": "# of approved files for this group"}, {"das": "group.dest_bytes", "ui": "# of approved bytes for this group"}, {"das": "group.node_bytes", "ui": "# of bytes archived on this node"}], "jobsummary": [{"das": "jobsummary.name", "ui": "Jobsummary", "link": [], "description": "provides dashboard summary information for given conditions", "examples": ["jobsummary user=Oli", "jobsummary date last 24h", "jobsummary site=T1_DE_KIT date last 24h", "jobsummary date between [20110208, 20110209]"]}, {"das": "jobsummary.terminated", "ui": "Terminated"}, {"das": "jobsummary.running", "ui": "Running"}, {"das": "jobsummary.submitted", "ui": "Submitted"}], "ip": [{"das": "ip.City", "ui": "City"}, {"das": "ip.CountryName", "ui": "Country"}, {"das": "ip.Latitude", "ui": "Latitude"}, {"das": "ip.Longitude", "ui": "Longitude"}], "city": [{"das": "city.name", "ui": "City"}, {"das": "city.Placemark.address", "ui": "Address"}, {"das": "city.Placemark.ExtendedData.LatLonBox.west", "ui": "Location west"}, {"das": "city.Placemark.ExtendedData.LatLonBox.east", "ui": "Location east"}, {"das": "city.Placemark.ExtendedData.LatLonBox.north", "ui": "Location north"}, {"das": "city.Placemark.ExtendedData.LatLonBox.south", "ui": "Location south"}], "parent": [{"das": "parent.name", "ui": "Parent name", "link": [], "description": "is a DAS key to lookup parent information of dataset/file", "examples": ["parent dataset=/a/b/c", "parent file=/lnf.root"]}, {"das": "parent.nevents", "ui": "Number of events"}, {"das": "parent.size", "ui": "Size"}], "child": [{"das": "child.name", "ui": "Child name", "link": [], "description": "is a DAS key to lookup child information of dataset/file", "examples": ["child dataset=/a/b/c", "child file=/lfn.root"]}, {"das": "child.nevents", "ui": "Number of events"}, {"das": "child.size", "ui": "Size"}], "config": [{"das": "config.name", "ui": "Request name", "link": [{"name": "ReqMgr info", "url": "https://cmsweb.cern.ch/reqmgr2/fetch?rid=%s"}], "description": "is a DAS key to lookup CMS configuration information about dataset configuration", "examples": []}, {"das": "config.ids", "ui": "Request urls"}, {"das": "config.type", "ui": "Type"}, {"das": "config.version", "ui": "Version"}, {"das": "config.release_version", "ui": "Release"}, {"das": "config.global_tag", "ui": "Global Tag"}, {"das": "config.pset_hash", "ui": "Pset hash"}, {"das": "config.create_by", "ui": "Created by"}, {"das": "config.creation_time", "ui": "Creation time"}], "user": [{"das": "user.name", "ui": "User name", "link": [], "description": "is a DAS key to specify CMS user name used in SiteDB (you can use either alias or user email)", "examples": ["user=oli", "user=oli@a.b.com"]}, {"das": "user.forename", "ui": "Fist name"}, {"das": "user.surname", "ui": "Last name"}, {"das": "user.dn", "ui": "User DN"}, {"das": "user.email", "ui": "Email"}, {"das": "user.phone1", "ui": "Phone"}, {"das": "user.phone2", "ui": "Alternative Phone"}], "mcm": [{"das": "mcm.prepid", "ui": "McM prepid", "link": [{"name": "Dataset", "query": "dataset prepid=%s"}], "description": "Most recent dataset for given prepid", "examples": ["dataset prepid=HIG-Summer12-01312"]}, {"das": "mcm.release", "ui": "CMSSW release"}, {"das": "mcm.notes", "ui": "Physics channel"}, {"das": "mcm.pwg", "ui": "Physics group"}, {"das": "mcm.nevents", "ui": "Number of events"}, {"das": "mcm.generator_parameters.cross_section", "ui": "Generator cross-secion"}], "status": [{"das": "status.name", "ui": "Status", "link": [], "description": "is a special DAS key to specify dataset status information", "examples": ["dataset status=valid"]}], "date": [{"das": "date", "ui": "", "link": [], "description": "is a special DAS key to specify date stamp in DAS queries, it can be used in conjunction with dataset/run/jobsummary/monitor DAS keys. The date cane be specified either using between clause or in form of last keyword", "examples": ["dataset date=20101103", "dataset date between [20101001, 20101002]", "run date = 20101009", "run date between [20101001, 20101002]", "jobsummary date last 24h", "jobsummary date between [20110208, 20110209]", "monitor date last 24h"]}], "das_query": [{"das": "das_query.dasquery", "ui": "DAS query"}, {"das": "das_query.counter", "ui": "Number of calls"}], "records": [{"das": "records", "ui": "Records", "link": [], "description": "is a special DAS key to look-up all records in DAS cache", "examples": ["records"]}]}, "type": "presentation", "hash": "presentationhash"}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmwm/das2go/utils"
+)
+
+// Span represents single operation of DAS query pipeline, it follows
+// OpenTelemetry span data model
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Name     string
+	Kind     int
+	Start    time.Time
+	Finish   time.Time
+	Attrs    map[string]interface{}
+	Error    string
+	mutex    sync.Mutex
+}
+
+// OpenTelemetry span kinds
+const (
+	SpanKindInternal = 1
+	SpanKindClient   = 3
+)
+
+// Tracer collects spans of DAS query and exports them to OTLP collector
+// or to a file in OTLP JSON data-format
+type Tracer struct {
+	Endpoint string
+	TraceID  string
+	ParentID string // span id of remote parent provided via W3C trace-context
+	Root     *Span
+	spans    []*Span
+	mutex    sync.Mutex
+}
+
+// spanKey is context key of span of outgoing HTTP request
+type spanKey struct{}
+
+// global tracer, nil means tracing is disabled
+var tracer *Tracer
+
+// helper function to generate random hex id of given size in bytes
+func randomID(size int) string {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return strings.Repeat("0", 2*size)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// NewTracer creates new tracer for given OTLP endpoint, the endpoint can be
+// either HTTP url of OTLP collector or file name (optionally prefixed by
+// file:) where spans will be written. If traceparent is provided in W3C
+// trace-context format the DAS query spans will be part of this trace.
+func NewTracer(endpoint, traceparent string) *Tracer {
+	t := &Tracer{Endpoint: endpoint}
+	arr := strings.Split(traceparent, "-")
+	if len(arr) == 4 && len(arr[1]) == 32 && len(arr[2]) == 16 {
+		t.TraceID = arr[1]
+		t.ParentID = arr[2]
+	} else {
+		t.TraceID = randomID(16)
+	}
+	return t
+}
+
+// Start creates new span with given name, if parent is nil the span
+// will become the root span of the trace
+func (t *Tracer) Start(name string, parent *Span) *Span {
+	if t == nil {
+		return nil
+	}
+	span := &Span{
+		TraceID: t.TraceID,
+		SpanID:  randomID(8),
+		Name:    name,
+		Kind:    SpanKindInternal,
+		Start:   time.Now(),
+		Attrs:   make(map[string]interface{}),
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if parent != nil {
+		span.ParentID = parent.SpanID
+	} else if t.Root != nil {
+		span.ParentID = t.Root.SpanID
+	} else {
+		span.ParentID = t.ParentID
+		t.Root = span
+	}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+// StartURL creates client span for given url fetch as a child of given
+// parent span, the span is used to propagate trace-context in outgoing
+// HTTP request
+func (t *Tracer) StartURL(rurl, service string, parent *Span) *Span {
+	if t == nil {
+		return nil
+	}
+	span := t.Start("fetch url", parent)
+	span.Kind = SpanKindClient
+	span.SetAttr("http.url", rurl)
+	span.SetAttr("das.service", service)
+	return span
+}
+
+// RootSpan returns root span of the trace
+func (t *Tracer) RootSpan() *Span {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.Root
+}
+
+// helper function to get context which carries span of outgoing HTTP
+// requests, i.e. every url fetch has its own span even if the same url is
+// fetched several times
+func contextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// helper function to get span of outgoing HTTP request from its context
+func contextSpan(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttr sets span attribute
+func (s *Span) SetAttr(key string, val interface{}) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	s.Attrs[key] = val
+	s.mutex.Unlock()
+}
+
+// SetError sets span error status
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mutex.Lock()
+	s.Error = err.Error()
+	s.mutex.Unlock()
+}
+
+// End sets span end time
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.Finish.IsZero() {
+		s.Finish = time.Now()
+	}
+	s.mutex.Unlock()
+}
+
+// TraceParent returns W3C trace-context header value of the span
+func (s *Span) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-01", s.TraceID, s.SpanID)
+}
+
+// helper function to convert span attributes into OTLP key-value list
+func otlpAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	var out []map[string]interface{}
+	for k, v := range attrs {
+		var val map[string]interface{}
+		switch a := v.(type) {
+		case int:
+			val = map[string]interface{}{"intValue": fmt.Sprintf("%d", a)}
+		case int64:
+			val = map[string]interface{}{"intValue": fmt.Sprintf("%d", a)}
+		case float64:
+			val = map[string]interface{}{"doubleValue": a}
+		case bool:
+			val = map[string]interface{}{"boolValue": a}
+		default:
+			val = map[string]interface{}{"stringValue": fmt.Sprintf("%v", a)}
+		}
+		out = append(out, map[string]interface{}{"key": k, "value": val})
+	}
+	return out
+}
+
+// OTLP returns OTLP JSON representation of collected spans
+func (t *Tracer) OTLP() ([]byte, error) {
+	t.mutex.Lock()
+	spans := t.spans
+	t.mutex.Unlock()
+	return otlpPayload(spans)
+}
+
+// helper function to get OTLP JSON payload of given spans
+func otlpPayload(list []*Span) ([]byte, error) {
+	var spans []map[string]interface{}
+	for _, s := range list {
+		s.End()
+		s.mutex.Lock()
+		span := map[string]interface{}{
+			"traceId":           s.TraceID,
+			"spanId":            s.SpanID,
+			"name":              s.Name,
+			"kind":              s.Kind,
+			"startTimeUnixNano": fmt.Sprintf("%d", s.Start.UnixNano()),
+			"endTimeUnixNano":   fmt.Sprintf("%d", s.Finish.UnixNano()),
+			"attributes":        otlpAttributes(s.Attrs),
+			"status":            map[string]interface{}{"code": 1},
+		}
+		if s.ParentID != "" {
+			span["parentSpanId"] = s.ParentID
+		}
+		if s.Error != "" {
+			span["status"] = map[string]interface{}{"code": 2, "message": s.Error}
+		}
+		s.mutex.Unlock()
+		spans = append(spans, span)
+	}
+	resource := map[string]interface{}{
+		"service.name":    "dasgoclient",
+		"service.version": utils.CLIENT_VERSION,
+	}
+	rec := map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{
+			{
+				"resource": map[string]interface{}{"attributes": otlpAttributes(resource)},
+				"scopeSpans": []map[string]interface{}{
+					{
+						"scope": map[string]interface{}{"name": "dasgoclient"},
+						"spans": spans,
+					},
+				},
+			},
+		},
+	}
+	return json.Marshal(rec)
+}
+
+// Flush exports collected spans to OTLP endpoint, exported spans are
+// released, i.e. every DAS query of a shell session exports its own spans
+func (t *Tracer) Flush() {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	spans := t.spans
+	t.spans = nil
+	t.mutex.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+	data, err := otlpPayload(spans)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: unable to marshal trace spans", err)
+		return
+	}
+	if strings.HasPrefix(t.Endpoint, "http://") || strings.HasPrefix(t.Endpoint, "https://") {
+		rurl := t.Endpoint
+		if !strings.HasSuffix(rurl, "/v1/traces") {
+			rurl = strings.TrimSuffix(rurl, "/") + "/v1/traces"
+		}
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Post(rurl, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR: unable to export trace spans", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			fmt.Fprintln(os.Stderr, "ERROR: unable to export trace spans, status", resp.Status)
+		}
+		return
+	}
+	// write spans to a file using OTLP JSON lines format
+	fname := strings.TrimPrefix(t.Endpoint, "file:")
+	file, err := os.OpenFile(fname, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: unable to export trace spans", err)
+		return
+	}
+	defer file.Close()
+	file.Write(append(data, '\n'))
+}
+
+// traceTransport is HTTP transport which injects W3C trace-context
+// header into outgoing HTTP requests
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := contextSpan(req.Context())
+	if span == nil {
+		span = tracer.RootSpan()
+	}
+	if span != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("traceparent", span.TraceParent())
+	}
+	resp, err := t.base.RoundTrip(req)
+	if span != nil {
+		if resp != nil {
+			span.SetAttr("http.status_code", resp.StatusCode)
+		}
+		span.SetError(err)
+	}
+	return resp, err
+}
+
+// helper function to wrap HTTP client transport with trace transport
+func traceClient(client *http.Client) *http.Client {
+	if tracer == nil {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &traceTransport{base: base}
+	return client
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dmwm/das2go/dasmaps"
+	"github.com/dmwm/das2go/dasql"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to decode OTLP JSON payload into its spans and resource
+// attributes
+func otlpSpans(t *testing.T, data []byte) ([]map[string]interface{}, map[string]interface{}) {
+	var rec struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []map[string]interface{} `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Scope map[string]interface{}   `json:"scope"`
+				Spans []map[string]interface{} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &rec))
+	assert.Equal(t, 1, len(rec.ResourceSpans))
+	assert.Equal(t, 1, len(rec.ResourceSpans[0].ScopeSpans))
+	resource := make(map[string]interface{})
+	for _, attr := range rec.ResourceSpans[0].Resource.Attributes {
+		resource[attr["key"].(string)] = attr["value"]
+	}
+	return rec.ResourceSpans[0].ScopeSpans[0].Spans, resource
+}
+
+// helper function to get OTLP span attributes keyed by their names
+func otlpSpanAttributes(span map[string]interface{}) map[string]interface{} {
+	attrs := make(map[string]interface{})
+	list, _ := span["attributes"].([]interface{})
+	for _, item := range list {
+		attr := item.(map[string]interface{})
+		attrs[attr["key"].(string)] = attr["value"]
+	}
+	return attrs
+}
+
+// TestTracer tests trace-context propagation and OTLP representation of spans
+func TestTracer(t *testing.T) {
+	assert := assert.New(t)
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	tracer = NewTracer("file:/dev/null", "00-"+traceID+"-00f067aa0ba902b7-01")
+	defer func() { tracer = nil }()
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	root := tracer.Start("dasgoclient", nil)
+	rurl := server.URL + "/dbs/prod/global/DBSReader/blocks?dataset=/a/b/c"
+	span := tracer.StartURL(rurl, "dbs", root)
+	client := contextClient(contextWithSpan(context.Background(), span), traceClient(&http.Client{}))
+	resp, err := client.Get(rurl)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(span.TraceParent(), header)
+	assert.Equal(root.SpanID, span.ParentID)
+	assert.Equal("00f067aa0ba902b7", root.ParentID)
+
+	data, err := tracer.OTLP()
+	assert.NoError(err)
+	var rec map[string]interface{}
+	assert.NoError(json.Unmarshal(data, &rec))
+	assert.True(strings.Contains(string(data), `"traceId":"`+traceID+`"`))
+	assert.True(strings.Contains(string(data), `"http.status_code","value":{"intValue":"200"}`))
+}
+
+// TestTracerParents tests parent-child relations of spans
+func TestTracerParents(t *testing.T) {
+	assert := assert.New(t)
+	var nilTracer *Tracer
+	assert.Nil(nilTracer.Start("dasgoclient", nil))
+	assert.Nil(nilTracer.StartURL("http://localhost", "dbs", nil))
+	nilTracer.Flush()
+
+	// invalid trace-context starts new trace
+	tr := NewTracer("file:/dev/null", "00-123-456-01")
+	assert.Equal(32, len(tr.TraceID))
+	assert.Equal("", tr.ParentID)
+	root := tr.Start("dasgoclient", nil)
+	assert.Equal(root, tr.Root)
+	assert.Equal("", root.ParentID)
+	assert.Equal(16, len(root.SpanID))
+
+	// spans without parent belong to the root span
+	parse := tr.Start("parse", nil)
+	assert.Equal(root.SpanID, parse.ParentID)
+	fetch := tr.Start("fetch", root)
+	api := tr.Start("local api", fetch)
+	rurl := "https://cmsweb.cern.ch/dbs/prod/global/DBSReader/datasets?dataset=/a/b/c"
+	span := tr.StartURL(rurl, "dbs", fetch)
+	assert.Equal(fetch.SpanID, api.ParentID)
+	assert.Equal(fetch.SpanID, span.ParentID)
+	assert.Equal(SpanKindClient, span.Kind)
+	assert.Equal(root, tr.RootSpan())
+	assert.Equal(span, contextSpan(contextWithSpan(context.Background(), span)))
+	assert.Nil(contextSpan(context.Background()))
+	for _, s := range []*Span{root, parse, fetch, api, span} {
+		assert.Equal(tr.TraceID, s.TraceID)
+	}
+	assert.Equal("00-"+tr.TraceID+"-"+span.SpanID+"-01", span.TraceParent())
+}
+
+// TestTracerFetch tests that url fetch spans of DAS query belong to its
+// fetch span
+func TestTracerFetch(t *testing.T) {
+	assert := assert.New(t)
+	tracer = NewTracer("file:/dev/null", "")
+	defer func() { tracer = nil }()
+
+	var mutex sync.Mutex
+	headers := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		headers[r.URL.Path] = r.Header.Get("traceparent")
+		mutex.Unlock()
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	root := tracer.Start("dasgoclient", nil)
+	fetch := tracer.Start("fetch", root)
+	dasquery := dasql.DASQuery{Fields: []string{"dataset"}}
+	for _, paths := range [][]string{{"/datasets"}, {"/blocks", "/files", "/runs"}} {
+		urls := make(map[string]string)
+		for _, path := range paths {
+			urls[server.URL+"/dbs/prod/global/DBSReader"+path+"?dataset=/a/b/c"] = ""
+		}
+		processURLs(context.Background(), dasquery, urls, nil, &dasmaps.DASMaps{}, []string{"dataset.name"}, fetch)
+		for rurl := range urls {
+			var span *Span
+			for _, s := range tracer.spans {
+				if s.Attrs["http.url"] == rurl {
+					span = s
+				}
+			}
+			assert.NotNil(span)
+			assert.Equal(fetch.SpanID, span.ParentID)
+			assert.Equal(span.TraceParent(), headers[strings.Split(strings.TrimPrefix(rurl, server.URL), "?")[0]])
+			assert.False(span.Finish.IsZero())
+		}
+	}
+}
+
+// TestTracerSameURL tests that every fetch of the same url has its own span
+func TestTracerSameURL(t *testing.T) {
+	assert := assert.New(t)
+	tracer = NewTracer("file:/dev/null", "")
+	defer func() { tracer = nil }()
+
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("traceparent"))
+	}))
+	defer server.Close()
+
+	root := tracer.Start("dasgoclient", nil)
+	rurl := server.URL + "/dbs/prod/global/DBSReader/datasets?dataset=/a/b/c"
+	var spans []*Span
+	for i := 0; i < 2; i++ {
+		span := tracer.StartURL(rurl, "dbs", root)
+		client := contextClient(contextWithSpan(context.Background(), span), traceClient(&http.Client{}))
+		resp, err := client.Get(rurl)
+		assert.NoError(err)
+		resp.Body.Close()
+		spans = append(spans, span)
+	}
+	// requests without span belong to the root span
+	resp, err := traceClient(&http.Client{}).Get(rurl)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal([]string{spans[0].TraceParent(), spans[1].TraceParent(), root.TraceParent()}, headers)
+	assert.NotEqual(spans[0].SpanID, spans[1].SpanID)
+}
+
+// TestTracerOTLP tests OTLP JSON payload of collected spans
+func TestTracerOTLP(t *testing.T) {
+	assert := assert.New(t)
+	tr := NewTracer("file:/dev/null", "")
+	root := tr.Start("dasgoclient", nil)
+	root.SetAttr("das.query", "dataset=/a/b/c")
+	root.SetAttr("das.exit_code", 0)
+	root.SetAttr("das.records", int64(3))
+	root.SetAttr("das.ratio", 0.5)
+	root.SetAttr("das.unique", true)
+	span := tr.Start("parse", root)
+	span.SetError(errors.New("das parser error"))
+	span.SetError(nil)
+	span.End()
+	finish := span.Finish
+	span.End()
+	assert.Equal(finish, span.Finish)
+
+	data, err := tr.OTLP()
+	assert.NoError(err)
+	spans, resource := otlpSpans(t, data)
+	assert.Equal(map[string]interface{}{"stringValue": "dasgoclient"}, resource["service.name"])
+	assert.NotNil(resource["service.version"])
+	assert.Equal(2, len(spans))
+
+	// root span is ended by export and has no parent
+	assert.Equal("dasgoclient", spans[0]["name"])
+	assert.Equal(tr.TraceID, spans[0]["traceId"])
+	assert.Equal(root.SpanID, spans[0]["spanId"])
+	assert.Nil(spans[0]["parentSpanId"])
+	assert.Equal(float64(SpanKindInternal), spans[0]["kind"])
+	assert.Equal(map[string]interface{}{"code": float64(1)}, spans[0]["status"])
+	assert.False(root.Finish.IsZero())
+	assert.True(spans[0]["endTimeUnixNano"].(string) >= spans[0]["startTimeUnixNano"].(string))
+	attrs := otlpSpanAttributes(spans[0])
+	assert.Equal(map[string]interface{}{"stringValue": "dataset=/a/b/c"}, attrs["das.query"])
+	assert.Equal(map[string]interface{}{"intValue": "0"}, attrs["das.exit_code"])
+	assert.Equal(map[string]interface{}{"intValue": "3"}, attrs["das.records"])
+	assert.Equal(map[string]interface{}{"doubleValue": 0.5}, attrs["das.ratio"])
+	assert.Equal(map[string]interface{}{"boolValue": true}, attrs["das.unique"])
+
+	// failed span carries error status
+	assert.Equal("parse", spans[1]["name"])
+	assert.Equal(root.SpanID, spans[1]["parentSpanId"])
+	assert.Equal(map[string]interface{}{"code": float64(2), "message": "das parser error"}, spans[1]["status"])
+}
+
+// TestTracerFlush tests export of spans to file and OTLP/HTTP collector
+func TestTracerFlush(t *testing.T) {
+	assert := assert.New(t)
+
+	// spans are appended to a file as OTLP JSON lines
+	dir := t.TempDir()
+	for _, endpoint := range []string{"file:" + filepath.Join(dir, "spans.json"), filepath.Join(dir, "spans.json")} {
+		tr := NewTracer(endpoint, "")
+		tr.Start("dasgoclient", nil)
+		tr.Flush()
+	}
+	file, err := os.Open(filepath.Join(dir, "spans.json"))
+	assert.NoError(err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var nlines int
+	for scanner.Scan() {
+		spans, _ := otlpSpans(t, scanner.Bytes())
+		assert.Equal(1, len(spans))
+		nlines++
+	}
+	assert.Equal(2, nlines)
+
+	// exported spans are released, i.e. every flush exports its own spans
+	tr := NewTracer("file:"+filepath.Join(dir, "query.json"), "")
+	root := tr.Start("dasgoclient", nil)
+	tr.Start("parse", root)
+	tr.Flush()
+	assert.Equal(0, len(tr.spans))
+	tr.Start("fetch", root)
+	tr.Flush()
+	data, err := os.ReadFile(filepath.Join(dir, "query.json"))
+	assert.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(2, len(lines))
+	spans, _ := otlpSpans(t, []byte(lines[1]))
+	assert.Equal(1, len(spans))
+	assert.Equal("fetch", spans[0]["name"])
+
+	// spans are posted to /v1/traces end-point of OTLP collector
+	var paths []string
+	var payload []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal("application/json", r.Header.Get("Content-Type"))
+		payload, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	for _, endpoint := range []string{server.URL, server.URL + "/", server.URL + "/v1/traces"} {
+		tr := NewTracer(endpoint, "")
+		root := tr.Start("dasgoclient", nil)
+		tr.Start("parse", root)
+		tr.Flush()
+		spans, _ := otlpSpans(t, payload)
+		assert.Equal(2, len(spans))
+	}
+	assert.Equal([]string{"/v1/traces", "/v1/traces", "/v1/traces"}, paths)
+}